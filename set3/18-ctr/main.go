@@ -5,10 +5,17 @@ import (
 	"crypto/cipher"
 	"encoding/base64"
 	"encoding/binary"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+
+	"github.com/dullgiulio/cryptopals-challenge/set3/randtest"
 )
 
+// 18-ctr
+// 18-ctr -stats
+
 func writeCRT(nonce, cnt uint64, buf []byte) {
 	binary.LittleEndian.PutUint64(buf, nonce)
 	binary.LittleEndian.PutUint64(buf[8:], cnt)
@@ -67,6 +74,8 @@ func decryptAesCtr(cph cipher.Block, nonce uint64, data []byte) []byte {
 }
 
 func main() {
+	stats := flag.Bool("stats", false, "run the randomness tests on the keystream")
+	flag.Parse()
 	nonce := uint64(0)
 	key := []byte("YELLOW SUBMARINE")
 	secret, err := base64.StdEncoding.DecodeString("L77na/nrFsKvynd6HzOoG7GHTLXsTVu9qvY/2syLXzhPweyyMTJULu/6/kXX0KSvoOLSFQ==")
@@ -77,6 +86,10 @@ func main() {
 	if err != nil {
 		log.Fatalf("cannot create AES cipher: %v", err)
 	}
+	if *stats {
+		randtest.Report(os.Stdout, "AES-CTR", decryptAesCtr(cph, nonce, make([]byte, 1<<17)))
+		return
+	}
 	plain := decryptAesCtr(cph, nonce, secret)
 	fmt.Printf("%s\n", plain)
 }
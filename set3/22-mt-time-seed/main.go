@@ -1,90 +1,351 @@
 package main
 
 import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/dullgiulio/cryptopals-challenge/set3/mt"
 )
 
-const (
-	N = 624
-	M = 397
-	R = 31
+// 22-mt-time-seed -fake
+// 22-mt-time-seed -unit ms -window 1h -pos 10 -workers 4 -fake
+// 22-mt-time-seed -build seeds.tab [-lo 0 -count 4294967296]
+// 22-mt-time-seed -table seeds.tab -output 1791095845
+// 22-mt-time-seed -unit ms -window 1h -pos 10 -output 1791095845
 
-	MASK_LOWER = uint32(uint64(1)<<R - 1)
-	MASK_UPPER = uint32(uint64(1) << R)
-)
+// clock is the source of time of the demo, so that it can run without
+// really sleeping.
+type clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time        { return time.Now() }
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// fakeClock only moves forward when sleeping
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time        { return c.now }
+func (c *fakeClock) Sleep(d time.Duration) { c.now = c.now.Add(d) }
 
-type rng struct {
-	mt    []uint32
-	index int
+// seedAt is the seed taken from a timestamp counted in units
+func seedAt(t time.Time, unit time.Duration) uint32 {
+	return uint32(t.UnixNano() / int64(unit))
 }
 
-func newRNG(seed uint32) *rng {
-	r := &rng{
-		mt:    make([]uint32, N),
-		index: N,
+// outputAt returns the output at position pos of the generator seeded with
+// seed. The first N-M outputs only need words pos, pos+1 and pos+M of the
+// seeded state, so the rest is not computed.
+func outputAt(seed uint32, pos int) uint32 {
+	if pos >= mt.N-mt.M {
+		r := mt.New(seed)
+		for i := 0; i < pos; i++ {
+			r.Uint32()
+		}
+		return r.Uint32()
+	}
+	var w [mt.N]uint32
+	w[0] = seed
+	for i := uint32(1); i <= uint32(pos+mt.M); i++ {
+		w[i] = 1812433253*(w[i-1]^w[i-1]>>30) + i
 	}
-	r.mt[0] = seed
-	for i := uint32(1); i < N; i++ {
-		r.mt[i] = 1812433253*(r.mt[i-1]^r.mt[i-1]>>30) + i
+	y := (w[pos] & 0x80000000) | (w[pos+1] & 0x7fffffff)
+	x := y >> 1
+	if y&1 != 0 {
+		x ^= 0x9908b0df
 	}
-	return r
+	return mt.Temper(w[pos+mt.M] ^ x)
 }
 
-func (r *rng) twist() {
-	for i := 0; i < N; i++ {
-		y := (r.mt[i] & 0x80000000) + (r.mt[(i+1)%N] & 0x7fffffff)
-		x := y >> 1
-		if y&1 != 0 {
-			x ^= 0x9908b0df
-		}
-		r.mt[i] = r.mt[(i+397)%624] ^ x
+// cracker finds the time used as seed from an output of the generator
+type cracker struct {
+	unit    time.Duration
+	workers int
+	pos     int
+}
+
+// crack searches the times between from and to for a seed whose output at
+// position pos is n. A range of more than 2^32 units covers all seeds and
+// only the first 2^32 units are searched.
+func (c *cracker) crack(n uint32, from, to time.Time) (time.Time, bool) {
+	lo := from.UnixNano() / int64(c.unit)
+	hi := to.UnixNano() / int64(c.unit)
+	if hi-lo >= 1<<32 {
+		hi = lo + 1<<32 - 1
+	}
+	var (
+		found int64
+		done  int32
+		wg    sync.WaitGroup
+	)
+	for w := 0; w < c.workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			i := 0
+			for t := lo + int64(w); t <= hi; t += int64(c.workers) {
+				i++
+				if i&1023 == 0 && atomic.LoadInt32(&done) != 0 {
+					return
+				}
+				if outputAt(uint32(t), c.pos) == n {
+					if atomic.CompareAndSwapInt32(&done, 0, 1) {
+						found = t
+					}
+					return
+				}
+			}
+		}(w)
 	}
-	r.index = 0
+	wg.Wait()
+	if done == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, found*int64(c.unit)), true
+}
+
+// the table is an index of tableBuckets+1 offsets, by the top 16 bits of
+// the first output, followed by the seeds sorted by their first output
+const tableBuckets = 1 << 16
+
+const headerSize = (tableBuckets + 1) * 8
+
+type pair struct {
+	output, seed uint32
 }
 
-func (r *rng) next() uint32 {
-	if r.index >= N {
-		r.twist()
-	}
-	i := r.index
-	y := r.mt[i]
-	r.index = i + 1
-	y = y ^ (y >> 11)
-	y = y ^ ((y << 7) & 2636928640)
-	y = y ^ ((y << 15) & 4022730752)
-	y = y ^ (y >> 18)
-	return y
+// buildTable writes the table for the seeds in [lo, lo+count). The seeds are
+// first spread in 256 temporary files by the top byte of the output, which
+// are then sorted one at a time.
+func buildTable(path string, lo, count uint64, workers int) error {
+	dir, err := ioutil.TempDir("", "mt-table")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	parts := make([]*os.File, 256)
+	writers := make([]*bufio.Writer, 256)
+	for i := range parts {
+		parts[i], err = os.Create(filepath.Join(dir, fmt.Sprintf("%02x", i)))
+		if err != nil {
+			return err
+		}
+		defer parts[i].Close()
+		writers[i] = bufio.NewWriter(parts[i])
+	}
+	const block = 1 << 20
+	outputs := make([]uint32, block)
+	buf := make([]byte, 8)
+	for start := uint64(0); start < count; start += block {
+		n := uint64(block)
+		if count-start < n {
+			n = count - start
+		}
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := uint64(w); i < n; i += uint64(workers) {
+					outputs[i] = outputAt(uint32(lo+start+i), 0)
+				}
+			}(w)
+		}
+		wg.Wait()
+		for i := uint64(0); i < n; i++ {
+			binary.LittleEndian.PutUint32(buf, outputs[i])
+			binary.LittleEndian.PutUint32(buf[4:], uint32(lo+start+i))
+			if _, err := writers[outputs[i]>>24].Write(buf); err != nil {
+				return err
+			}
+		}
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	w := bufio.NewWriter(out)
+	if _, err := w.Write(make([]byte, headerSize)); err != nil {
+		return err
+	}
+	header := make([]byte, headerSize)
+	offset := uint64(headerSize)
+	bucket := 0
+	for i, part := range parts {
+		if err := writers[i].Flush(); err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(part.Name())
+		if err != nil {
+			return err
+		}
+		pairs := make([]pair, len(data)/8)
+		for j := range pairs {
+			pairs[j] = pair{binary.LittleEndian.Uint32(data[8*j:]), binary.LittleEndian.Uint32(data[8*j+4:])}
+		}
+		sort.Slice(pairs, func(a, b int) bool { return pairs[a].output < pairs[b].output })
+		for _, p := range pairs {
+			for ; bucket <= int(p.output>>16); bucket++ {
+				binary.LittleEndian.PutUint64(header[8*bucket:], offset)
+			}
+			binary.LittleEndian.PutUint32(buf, p.seed)
+			if _, err := w.Write(buf[:4]); err != nil {
+				return err
+			}
+			offset += 4
+		}
+	}
+	for ; bucket <= tableBuckets; bucket++ {
+		binary.LittleEndian.PutUint64(header[8*bucket:], offset)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	_, err = out.WriteAt(header, 0)
+	return err
 }
 
-func guessSeed(n uint32) uint32 {
-	t := uint32(time.Now().Unix())
-	for i := uint32(39); i < uint32(1010); i++ {
-		rng := newRNG(t - i)
-		if rng.next() == n {
-			return t - i
+// lookupTable returns the seeds whose first output is n
+func lookupTable(path string, n uint32) ([]uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b := make([]byte, 16)
+	if _, err := f.ReadAt(b, int64(8*(n>>16))); err != nil {
+		return nil, err
+	}
+	start := binary.LittleEndian.Uint64(b)
+	end := binary.LittleEndian.Uint64(b[8:])
+	var rerr error
+	read := func(i int) uint32 {
+		if _, err := f.ReadAt(b[:4], int64(start)+4*int64(i)); err != nil && err != io.EOF {
+			rerr = err
+		}
+		return binary.LittleEndian.Uint32(b)
+	}
+	count := int((end - start) / 4)
+	i := sort.Search(count, func(i int) bool { return outputAt(read(i), 0) >= n })
+	var seeds []uint32
+	for ; i < count; i++ {
+		s := read(i)
+		if outputAt(s, 0) != n {
+			break
 		}
+		seeds = append(seeds, s)
+	}
+	return seeds, rerr
+}
+
+// demo waits a random time, seeds with the time, waits again and cracks the
+// seed from an output.
+func demo(clk clock, rnd *rand.Rand, c *cracker, window time.Duration) (uint32, uint32, error) {
+	min, max := int32(40), int32(1000)
+	clk.Sleep(time.Duration(rnd.Int31n(max-min)+min) * time.Second)
+	seed := seedAt(clk.Now(), c.unit)
+	rng := mt.New(seed)
+	clk.Sleep(time.Duration(rnd.Int31n(max-min)+min) * time.Second)
+	for i := 0; i < c.pos; i++ {
+		rng.Uint32()
+	}
+	n := rng.Uint32()
+	now := clk.Now()
+	t, ok := c.crack(n, now.Add(-window), now)
+	if !ok {
+		return seed, 0, errors.New("seed not found")
 	}
-	return 0
+	return seed, seedAt(t, c.unit), nil
+}
+
+var units = map[string]time.Duration{
+	"s":  time.Second,
+	"ms": time.Millisecond,
+	"ns": time.Nanosecond,
 }
 
 func main() {
+	unit := flag.String("unit", "s", "resolution of the seed timestamps: s, ms or ns")
+	window := flag.Duration("window", 2000*time.Second, "how far back to search")
+	pos := flag.Int("pos", 0, "position of the observed output in the stream")
+	workers := flag.Int("workers", runtime.NumCPU(), "parallel workers")
+	fake := flag.Bool("fake", false, "simulate the waits instead of sleeping")
+	build := flag.String("build", "", "build a table of first outputs to this file")
+	lo := flag.Uint64("lo", 0, "first seed in the table")
+	count := flag.Uint64("count", 1<<32, "number of seeds in the table")
+	table := flag.String("table", "", "look up an output in this table")
+	output := flag.Uint("output", 0, "observed output to crack, or first output to look up")
+	flag.Parse()
+
+	observed := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "output" {
+			observed = true
+		}
+	})
+
+	if *workers < 1 {
+		log.Fatalf("need at least one worker, got %d", *workers)
+	}
+	if *build != "" {
+		if err := buildTable(*build, *lo, *count, *workers); err != nil {
+			log.Fatalf("cannot build table: %v", err)
+		}
+		return
+	}
+	if *table != "" {
+		seeds, err := lookupTable(*table, uint32(*output))
+		if err != nil {
+			log.Fatalf("cannot read table: %v", err)
+		}
+		fmt.Printf("seeds: %v\n", seeds)
+		return
+	}
+
+	u, ok := units[*unit]
+	if !ok {
+		log.Fatalf("unknown unit %s", *unit)
+	}
+	var clk clock = realClock{}
+	if *fake {
+		clk = &fakeClock{time.Now()}
+	}
+	c := &cracker{unit: u, workers: *workers, pos: *pos}
+	if observed {
+		now := clk.Now()
+		t, ok := c.crack(uint32(*output), now.Add(-*window), now)
+		if !ok {
+			log.Fatalf("no seed in the last %s gives %d at position %d", *window, *output, *pos)
+		}
+		fmt.Printf("seed %d, seeded at %s\n", seedAt(t, u), t)
+		return
+	}
 	// no, I am not using my rng when I can avoid it
 	trand := rand.New(rand.NewSource(time.Now().UnixNano()))
-	min, max := int32(40), int32(1000)
-	for {
-		sleep := time.Duration(trand.Int31n(max-min)+min) * time.Second
-		time.Sleep(sleep)
-		seed := uint32(time.Now().Unix())
-		rng := newRNG(seed)
-		sleep = time.Duration(trand.Int31n(max-min)+min) * time.Second
-		time.Sleep(sleep)
-		n := rng.next()
-		guess := guessSeed(n)
-		if guess == seed {
-			fmt.Printf("Guessed %d\n", guess)
-			break
-		}
+	seed, guess, err := demo(clk, trand, c, *window)
+	if err != nil {
+		log.Fatalf("cannot crack seed %d: %v", seed, err)
 	}
+	fmt.Printf("Guessed %d, seed was %d\n", guess, seed)
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dullgiulio/cryptopals-challenge/set3/mt"
+)
+
+func TestOutputAt(t *testing.T) {
+	seed := rand.Uint32()
+	r := mt.New(seed)
+	for pos := 0; pos < 700; pos++ {
+		if n, exp := outputAt(seed, pos), r.Uint32(); n != exp {
+			t.Fatalf("position %d: expected %d, got %d", pos, exp, n)
+		}
+	}
+}
+
+func TestCrack(t *testing.T) {
+	now := time.Unix(1500000000, 123456789)
+	c := &cracker{unit: time.Millisecond, workers: 3, pos: 5}
+	seed := seedAt(now.Add(-42*time.Second), c.unit)
+	n := outputAt(seed, c.pos)
+	found, ok := c.crack(n, now.Add(-time.Minute), now)
+	if !ok {
+		t.Fatal("seed not found")
+	}
+	if s := seedAt(found, c.unit); s != seed {
+		t.Fatalf("expected seed %d, got %d", seed, s)
+	}
+	if _, ok := c.crack(n, now.Add(-time.Second), now); ok {
+		t.Fatal("seed found outside of the window")
+	}
+}
+
+func TestDemo(t *testing.T) {
+	clk := &fakeClock{time.Unix(1500000000, 0)}
+	rnd := rand.New(rand.NewSource(1))
+	c := &cracker{unit: time.Second, workers: 2}
+	seed, guess, err := demo(clk, rnd, c, 2000*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seed != guess {
+		t.Fatalf("guessed %d, seed was %d", guess, seed)
+	}
+	if clk.Now().Sub(time.Unix(1500000000, 0)) < 80*time.Second {
+		t.Fatal("fake clock did not move")
+	}
+}
+
+func TestTable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mt-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "seeds.tab")
+	if err := buildTable(path, 1000, 1<<14, 2); err != nil {
+		t.Fatal(err)
+	}
+	for _, seed := range []uint32{1000, 5000, 1000 + 1<<14 - 1} {
+		seeds, err := lookupTable(path, outputAt(seed, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(seeds) != 1 || seeds[0] != seed {
+			t.Fatalf("expected seed %d, found %v", seed, seeds)
+		}
+	}
+	seeds, err := lookupTable(path, outputAt(999, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seeds) != 0 {
+		t.Fatalf("found seeds %v outside of the table", seeds)
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"math/bits"
+
+	"github.com/dullgiulio/cryptopals-challenge/set3/randtest"
+)
+
+// bitSource produces a keystream one bit at a time
+type bitSource interface {
+	bit() byte
+}
+
+// lfsr is a linear feedback shift register with connection polynomial
+// poly, where bit j is the coefficient of x^j: the output bits follow
+// s[t] = c1*s[t-1] + ... + cL*s[t-L]. In Fibonacci form the register holds
+// the next L outputs, in Galois form the feedback is spread in the register
+// and the outputs are different, but follow the same recurrence.
+type lfsr struct {
+	n      uint
+	mask   uint64
+	state  uint64
+	galois bool
+}
+
+func newLFSR(poly, state uint64, galois bool) *lfsr {
+	n := uint(bits.Len64(poly)) - 1
+	l := &lfsr{n: n, galois: galois, state: state & (1<<n - 1)}
+	for j := uint(1); j <= n; j++ {
+		if poly&(1<<j) == 0 {
+			continue
+		}
+		if galois {
+			l.mask |= 1 << (j - 1)
+		} else {
+			// s[t+L-j] is at bit L-j of the register
+			l.mask |= 1 << (n - j)
+		}
+	}
+	return l
+}
+
+func (l *lfsr) bit() byte {
+	out := byte(l.state & 1)
+	if l.galois {
+		l.state >>= 1
+		if out != 0 {
+			l.state ^= l.mask
+		}
+		return out
+	}
+	fb := uint64(bits.OnesCount64(l.state&l.mask) & 1)
+	l.state = l.state>>1 | fb<<(l.n-1)
+	return out
+}
+
+// geffe uses x1 to choose between the outputs of x2 and x3
+type geffe struct {
+	x1, x2, x3 bitSource
+}
+
+func (g *geffe) bit() byte {
+	a, b, c := g.x1.bit(), g.x2.bit(), g.x3.bit()
+	return a&b | (a^1)&c
+}
+
+// shrinking outputs the bits of a when s outputs 1
+type shrinking struct {
+	a, s bitSource
+}
+
+func (g *shrinking) bit() byte {
+	for {
+		a, s := g.a.bit(), g.s.bit()
+		if s == 1 {
+			return a
+		}
+	}
+}
+
+// writeBits fills buf with bits from src, least significant first
+func writeBits(src bitSource, buf []byte) {
+	for i := range buf {
+		var b byte
+		for j := uint(0); j < 8; j++ {
+			b |= src.bit() << j
+		}
+		buf[i] = b
+	}
+}
+
+// bitsOf splits bytes in bits, least significant first
+func bitsOf(bs []byte) []byte {
+	s := make([]byte, 0, 8*len(bs))
+	for _, b := range bs {
+		for j := uint(0); j < 8; j++ {
+			s = append(s, b>>j&1)
+		}
+	}
+	return s
+}
+
+type bitStream struct {
+	src bitSource
+}
+
+func (c *bitStream) crypt(dst, src []byte) {
+	buf := make([]byte, len(src))
+	writeBits(c.src, buf)
+	xorBytes(dst, src, buf)
+}
+
+// recoverLFSR finds the Fibonacci LFSR that produced the keystream bits,
+// positioned after them. It is limited to 63 bits registers.
+func recoverLFSR(s []byte) *lfsr {
+	l, c := randtest.BerlekampMassey(s)
+	var poly, state uint64
+	for j, b := range c {
+		poly |= uint64(b) << uint(j)
+	}
+	// a polynomial of lower degree, as for an all zero stream, still
+	// needs a register of L bits
+	poly |= 1 << uint(l)
+	for i := 0; i < l; i++ {
+		state |= uint64(s[i]) << uint(i)
+	}
+	r := newLFSR(poly, state, false)
+	for range s {
+		r.bit()
+	}
+	return r
+}
+
+// correlation counts the bits of the LFSR from state that agree with s
+func correlation(poly, state uint64, s []byte) int {
+	l := newLFSR(poly, state, false)
+	n := 0
+	for _, b := range s {
+		if l.bit() == b {
+			n++
+		}
+	}
+	return n
+}
+
+// bestState returns the state of the LFSR that agrees the most with s
+func bestState(poly uint64, s []byte) uint64 {
+	n := uint(bits.Len64(poly)) - 1
+	var best uint64
+	most := -1
+	for st := uint64(1); st < 1<<n; st++ {
+		if c := correlation(poly, st, s); c > most {
+			best, most = st, c
+		}
+	}
+	return best
+}
+
+// crackGeffe recovers the states of the Fibonacci LFSRs of a Geffe
+// generator from its output. The output agrees with x2 and with x3 three
+// times out of four, so each is found alone by trying all its states,
+// leaving only x1 to brute force.
+func crackGeffe(p1, p2, p3 uint64, s []byte) (uint64, uint64, uint64, bool) {
+	s2 := bestState(p2, s)
+	s3 := bestState(p3, s)
+	n := uint(bits.Len64(p1)) - 1
+	for s1 := uint64(1); s1 < 1<<n; s1++ {
+		g := &geffe{newLFSR(p1, s1, false), newLFSR(p2, s2, false), newLFSR(p3, s3, false)}
+		ok := true
+		for _, b := range s {
+			if g.bit() != b {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return s1, s2, s3, true
+		}
+	}
+	return 0, 0, 0, false
+}
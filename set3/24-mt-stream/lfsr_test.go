@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dullgiulio/cryptopals-challenge/set3/randtest"
+)
+
+func TestBerlekampMassey(t *testing.T) {
+	// x^19 + x^5 + x^2 + x + 1 is primitive
+	const poly = 0x80027
+	for _, galois := range []bool{false, true} {
+		s := make([]byte, 38)
+		l := newLFSR(poly, 0x1234, galois)
+		for i := range s {
+			s[i] = l.bit()
+		}
+		n, c := randtest.BerlekampMassey(s)
+		if n != 19 {
+			t.Fatalf("galois %v: expected linear complexity 19, got %d", galois, n)
+		}
+		var p uint64
+		for j, b := range c {
+			p |= uint64(b) << uint(j)
+		}
+		if p != poly {
+			t.Fatalf("galois %v: expected polynomial %x, got %x", galois, poly, p)
+		}
+	}
+}
+
+func TestRecoverLFSR(t *testing.T) {
+	l := newLFSR(0x1002d, 0xbeef, true)
+	ks := make([]byte, 100)
+	writeBits(l, ks)
+	r := recoverLFSR(bitsOf(ks[:4]))
+	rest := make([]byte, len(ks)-4)
+	writeBits(r, rest)
+	if !bytes.Equal(rest, ks[4:]) {
+		t.Fatalf("expected %x, got %x", ks[4:], rest)
+	}
+}
+
+func TestShrinking(t *testing.T) {
+	g := &shrinking{newLFSR(0x805, 1, false), newLFSR(0x201b, 1, false)}
+	s := make([]byte, 64)
+	writeBits(g, s)
+	// far more than the 11 + 13 bits of the registers
+	if n, _ := randtest.BerlekampMassey(bitsOf(s)); n < 100 {
+		t.Fatalf("linear complexity %d of the shrinking generator", n)
+	}
+}
+
+func TestCrackGeffe(t *testing.T) {
+	const p1, p2, p3 = 0x805, 0x201b, 0x20009
+	g := &geffe{newLFSR(p1, 0x123, false), newLFSR(p2, 0x456, false), newLFSR(p3, 0x789, false)}
+	ks := make([]byte, 48)
+	writeBits(g, ks)
+	s1, s2, s3, ok := crackGeffe(p1, p2, p3, bitsOf(ks))
+	if !ok {
+		t.Fatal("states not found")
+	}
+	if s1 != 0x123 || s2 != 0x456 || s3 != 0x789 {
+		t.Fatalf("wrong states %x %x %x", s1, s2, s3)
+	}
+}
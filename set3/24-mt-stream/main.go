@@ -3,70 +3,28 @@ package main
 import (
 	"bytes"
 	"encoding/binary"
+	"flag"
 	"fmt"
+	"log"
+	"math/rand"
+	"os"
 	"time"
-)
-
-const (
-	N = 624
-	M = 397
-	R = 31
 
-	MASK_LOWER = uint32(uint64(1)<<R - 1)
-	MASK_UPPER = uint32(uint64(1) << R)
+	"github.com/dullgiulio/cryptopals-challenge/set3/mt"
+	"github.com/dullgiulio/cryptopals-challenge/set3/randtest"
 )
 
-type rng struct {
-	mt    []uint32
-	index int
-}
-
-func newRNG(seed uint32) *rng {
-	r := &rng{
-		mt:    make([]uint32, N),
-		index: N,
-	}
-	r.mt[0] = seed
-	for i := uint32(1); i < N; i++ {
-		r.mt[i] = 1812433253*(r.mt[i-1]^r.mt[i-1]>>30) + i
-	}
-	return r
-}
-
-func (r *rng) twist() {
-	for i := 0; i < N; i++ {
-		y := (r.mt[i] & 0x80000000) + (r.mt[(i+1)%N] & 0x7fffffff)
-		x := y >> 1
-		if y&1 != 0 {
-			x ^= 0x9908b0df
-		}
-		r.mt[i] = r.mt[(i+397)%624] ^ x
-	}
-	r.index = 0
-}
+// 24-mt-stream
+// 24-mt-stream -stats
 
-func (r *rng) next() uint32 {
-	if r.index >= N {
-		r.twist()
-	}
-	i := r.index
-	y := r.mt[i]
-	r.index = i + 1
-	y = y ^ (y >> 11)
-	y = y ^ ((y << 7) & 2636928640)
-	y = y ^ ((y << 15) & 4022730752)
-	y = y ^ (y >> 18)
-	return y
-}
-
-func writeCipher(rng *rng, buf []byte) {
-	for i := 0; i < len(buf)/4; i += 4 {
-		binary.LittleEndian.PutUint32(buf[i:], rng.next())
+func writeCipher(rng *mt.MT19937, buf []byte) {
+	for i := 0; i+4 <= len(buf); i += 4 {
+		binary.LittleEndian.PutUint32(buf[i:], rng.Uint32())
 	}
 	n := len(buf) % 4
 	if len(buf)%4 != 0 {
 		bs := make([]byte, 4)
-		binary.LittleEndian.PutUint32(bs, rng.next())
+		binary.LittleEndian.PutUint32(bs, rng.Uint32())
 		copy(buf[len(buf)-n:], bs[:n])
 	}
 }
@@ -80,12 +38,12 @@ func xorBytes(dst, a, b []byte) {
 }
 
 type ctrRng struct {
-	rng *rng
+	rng *mt.MT19937
 }
 
 func newRngEnc(key uint32) *ctrRng {
 	return &ctrRng{
-		rng: newRNG(key),
+		rng: mt.New(key),
 	}
 }
 
@@ -109,7 +67,122 @@ func trytime(expected, ctxt []byte, tmin, tmax time.Time) time.Time {
 	return time.Time{}
 }
 
+// encryptWithPrefix encrypts the known plaintext after a random prefix of
+// random length with a 16 bit key
+func encryptWithPrefix(key uint16, known []byte) []byte {
+	prefix := make([]byte, 5+rand.Intn(20))
+	rand.Read(prefix)
+	plain := append(prefix, known...)
+	ctxt := make([]byte, len(plain))
+	newRngEnc(uint32(key)).crypt(ctxt, plain)
+	return ctxt
+}
+
+// crackKey tries all 16 bit keys, the right one decrypts to the known
+// suffix.
+func crackKey(ctxt, known []byte) (uint16, bool) {
+	if len(ctxt) < len(known) {
+		return 0, false
+	}
+	buf := make([]byte, len(ctxt))
+	for k := 0; k < 1<<16; k++ {
+		newRngEnc(uint32(k)).crypt(buf, ctxt)
+		if bytes.HasSuffix(buf, known) {
+			return uint16(k), true
+		}
+	}
+	return 0, false
+}
+
+// resetToken is a password reset token from the keystream of the generator
+// seeded with the time.
+func resetToken(now time.Time) []byte {
+	token := make([]byte, 16)
+	writeCipher(mt.New(uint32(now.Unix())), token)
+	return token
+}
+
+// isTimeToken tells if the token comes from a generator seeded with a time
+// up to window before now.
+func isTimeToken(token []byte, now time.Time, window time.Duration) bool {
+	zero := make([]byte, len(token))
+	return !trytime(token, zero, now.Add(-window), now).IsZero()
+}
+
+// lfsrDemo decrypts an LFSR stream from a known prefix, then breaks a
+// Geffe generator.
+func lfsrDemo() {
+	plain := []byte("known header: the rest of the message is not known to the attacker")
+	known := 12
+	ctxt := make([]byte, len(plain))
+	(&bitStream{newLFSR(0x80027, rand.Uint64()|1, true)}).crypt(ctxt, plain)
+	ks := make([]byte, known)
+	xorBytes(ks, ctxt[:known], plain[:known])
+	r := recoverLFSR(bitsOf(ks))
+	dec := make([]byte, len(ctxt)-known)
+	(&bitStream{r}).crypt(dec, ctxt[known:])
+	fmt.Printf("LFSR of %d bits, decrypted: %s\n", r.n, dec)
+
+	s1, s2, s3 := rand.Uint64()|1, rand.Uint64()|1, rand.Uint64()|1
+	g := &geffe{newLFSR(0x201b, s1, false), newLFSR(0x20009, s2, false), newLFSR(0x80027, s3, false)}
+	ks = make([]byte, 64)
+	writeBits(g, ks)
+	c1, c2, c3, ok := crackGeffe(0x201b, 0x20009, 0x80027, bitsOf(ks))
+	if !ok {
+		log.Fatal("Geffe states not found")
+	}
+	fmt.Printf("Geffe states %x %x %x, found %x %x %x\n", s1&(1<<13-1), s2&(1<<17-1), s3&(1<<19-1), c1, c2, c3)
+}
+
+// stats runs the randomness tests on MT and on the ctrRng keystream. MT
+// passes them, yet its outputs are enough to clone it.
+func stats(size int) {
+	seed := rand.Uint32()
+	buf := make([]byte, size)
+	rng := mt.New(seed)
+	writeCipher(rng, buf)
+	randtest.Report(os.Stdout, "MT19937", buf)
+
+	ks := make([]byte, size)
+	newRngEnc(uint32(rand.Intn(1<<16))).crypt(ks, ks)
+	randtest.Report(os.Stdout, "ctrRng", ks)
+
+	outputs := make([]uint32, mt.N)
+	for i := range outputs {
+		outputs[i] = binary.LittleEndian.Uint32(buf[4*i:])
+	}
+	clone, err := mt.Clone(outputs)
+	if err != nil {
+		log.Fatalf("cannot clone: %v", err)
+	}
+	for i := mt.N; i < size/4; i++ {
+		clone.Uint32()
+	}
+	fmt.Printf("next output %d, predicted %d\n", rng.Uint32(), clone.Uint32())
+}
+
 func main() {
+	doStats := flag.Bool("stats", false, "run the randomness tests on the keystreams")
+	flag.Parse()
+	rand.Seed(time.Now().UnixNano())
+	if *doStats {
+		stats(1 << 17)
+		return
+	}
+	known := []byte("AAAAAAAAAAAAAA")
+	key := uint16(rand.Intn(1 << 16))
+	k, ok := crackKey(encryptWithPrefix(key, known), known)
+	if !ok {
+		log.Fatal("key not found")
+	}
+	fmt.Printf("key %d, found %d\n", key, k)
+
+	reset := resetToken(time.Now())
+	random := make([]byte, len(reset))
+	rand.Read(random)
+	fmt.Printf("token from time: %v, random token from time: %v\n",
+		isTimeToken(reset, time.Now(), time.Minute), isTimeToken(random, time.Now(), time.Minute))
+
 	mail := []byte("test@example.com")
 	t := uint32(time.Now().Unix())
 	token := make([]byte, len(mail))
@@ -117,4 +190,6 @@ func main() {
 	tm := time.Now()
 	tm = trytime(mail, token, tm.Add(-1*time.Minute), tm)
 	fmt.Printf("%s\n", tm)
+
+	lfsrDemo()
 }
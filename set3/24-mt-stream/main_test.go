@@ -3,6 +3,9 @@ package main
 import (
 	"bytes"
 	"testing"
+	"time"
+
+	"github.com/dullgiulio/cryptopals-challenge/set3/mt"
 )
 
 func TestRng(t *testing.T) {
@@ -17,3 +20,52 @@ func TestRng(t *testing.T) {
 		t.Fatalf("'%s' != '%s'\n", ptxt, plain)
 	}
 }
+
+func TestWriteCipher(t *testing.T) {
+	for _, n := range []int{3, 4, 9, 16, 31} {
+		buf := make([]byte, n)
+		writeCipher(mt.New(42), buf)
+		r := mt.New(42)
+		rem := n % 4
+		exp := make([]byte, 0, n)
+		for len(exp) < n-rem {
+			w := r.Uint32()
+			exp = append(exp, byte(w), byte(w>>8), byte(w>>16), byte(w>>24))
+		}
+		if bytes.Compare(buf[:n-rem], exp) != 0 {
+			t.Fatalf("length %d: expected %x, got %x", n, exp, buf[:n-rem])
+		}
+		last := r.Uint32()
+		for i, b := range buf[n-rem:] {
+			if b != byte(last>>(8*uint(i))) {
+				t.Fatalf("length %d: byte %d is %02x, not from output %08x", n, n-rem+i, b, last)
+			}
+		}
+	}
+}
+
+func TestCrackKey(t *testing.T) {
+	known := []byte("AAAAAAAAAAAAAA")
+	ctxt := encryptWithPrefix(31337, known)
+	k, ok := crackKey(ctxt, known)
+	if !ok {
+		t.Fatal("key not found")
+	}
+	if k != 31337 {
+		t.Fatalf("expected key 31337, got %d", k)
+	}
+}
+
+func TestTimeToken(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	token := resetToken(now.Add(-10 * time.Second))
+	if !isTimeToken(token, now, time.Minute) {
+		t.Fatal("token from time not detected")
+	}
+	if isTimeToken(token, now.Add(-time.Hour), time.Minute) {
+		t.Fatal("token detected outside of the window")
+	}
+	if isTimeToken([]byte("0123456789abcdef"), now, time.Minute) {
+		t.Fatal("random token detected")
+	}
+}
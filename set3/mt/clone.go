@@ -0,0 +1,70 @@
+package mt
+
+import "errors"
+
+var errOutputs = errors.New("mt: need a full state worth of consecutive outputs")
+
+// undoRight inverts y ^= (y >> s) & mask, each round recovers s more bits.
+func undoRight(y uint64, s uint, mask uint64) uint64 {
+	z := y
+	for i := uint(0); i < 64; i += s {
+		z = y ^ (z>>s)&mask
+	}
+	return z
+}
+
+// undoLeft inverts y ^= (y << s) & mask
+func undoLeft(y uint64, s uint, mask uint64) uint64 {
+	z := y
+	for i := uint(0); i < 64; i += s {
+		z = y ^ (z<<s)&mask
+	}
+	return z
+}
+
+// Untemper inverts Temper.
+func Untemper(y uint32) uint32 {
+	z := undoRight(uint64(y), 18, 0xffffffff)
+	z = undoLeft(z, 15, 0xefc60000)
+	z = undoLeft(z, 7, 0x9d2c5680)
+	z = undoRight(z, 11, 0xffffffff)
+	return uint32(z)
+}
+
+// Untemper64 inverts Temper64.
+func Untemper64(y uint64) uint64 {
+	y = undoRight(y, 43, 0xffffffffffffffff)
+	y = undoLeft(y, 37, 0xfff7eee000000000)
+	y = undoLeft(y, 17, 0x71d67fffeda60000)
+	y = undoRight(y, 29, 0x5555555555555555)
+	return y
+}
+
+// Clone returns a generator that continues the sequence of the first N
+// outputs, which have to start right after a twist.
+func Clone(outputs []uint32) (*MT19937, error) {
+	if len(outputs) < N {
+		return nil, errOutputs
+	}
+	state := make([]uint32, N)
+	for i := range state {
+		state[i] = Untemper(outputs[i])
+	}
+	r := &MT19937{}
+	r.SetState(state)
+	return r, nil
+}
+
+// Clone64 is Clone for the 64-bit generator, it needs NN outputs.
+func Clone64(outputs []uint64) (*MT64, error) {
+	if len(outputs) < NN {
+		return nil, errOutputs
+	}
+	state := make([]uint64, NN)
+	for i := range state {
+		state[i] = Untemper64(outputs[i])
+	}
+	r := &MT64{}
+	r.SetState(state)
+	return r, nil
+}
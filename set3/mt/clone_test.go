@@ -0,0 +1,56 @@
+package mt
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestUntemper(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		x := rand.Uint32()
+		if y := Untemper(Temper(x)); y != x {
+			t.Fatalf("untemper(temper(%x)) = %x", x, y)
+		}
+		z := rand.Uint64()
+		if y := Untemper64(Temper64(z)); y != z {
+			t.Fatalf("untemper64(temper64(%x)) = %x", z, y)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	r := New(rand.Uint32())
+	outputs := make([]uint32, N)
+	for i := range outputs {
+		outputs[i] = r.Uint32()
+	}
+	c, err := Clone(outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if x, y := r.Uint32(), c.Uint32(); x != y {
+			t.Fatalf("output %d: %d != %d", i, x, y)
+		}
+	}
+	if _, err := Clone(outputs[1:]); err == nil {
+		t.Fatal("cloned from too few outputs")
+	}
+}
+
+func TestClone64(t *testing.T) {
+	r := New64(rand.Uint64())
+	outputs := make([]uint64, NN)
+	for i := range outputs {
+		outputs[i] = r.Uint64()
+	}
+	c, err := Clone64(outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if x, y := r.Uint64(), c.Uint64(); x != y {
+			t.Fatalf("output %d: %d != %d", i, x, y)
+		}
+	}
+}
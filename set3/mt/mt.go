@@ -0,0 +1,168 @@
+// Package mt implements the MT19937 Mersenne Twister as in the reference
+// mt19937ar.c, usable as a math/rand source.
+package mt
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	N = 624
+	M = 397
+
+	matrixA   = 0x9908b0df
+	upperMask = 0x80000000
+	lowerMask = 0x7fffffff
+)
+
+// MT19937 is a 32-bit Mersenne Twister. It implements rand.Source64.
+type MT19937 struct {
+	mt    [N]uint32
+	index int
+}
+
+// New returns a generator seeded with init_genrand(seed).
+func New(seed uint32) *MT19937 {
+	r := &MT19937{}
+	r.seed(seed)
+	return r
+}
+
+// NewByArray returns a generator seeded with init_by_array(key).
+func NewByArray(key []uint32) *MT19937 {
+	r := &MT19937{}
+	r.SeedByArray(key)
+	return r
+}
+
+func (r *MT19937) seed(s uint32) {
+	r.mt[0] = s
+	for i := uint32(1); i < N; i++ {
+		r.mt[i] = 1812433253*(r.mt[i-1]^r.mt[i-1]>>30) + i
+	}
+	r.index = N
+}
+
+// Seed uses the low 32 bits of seed, for rand.Source.
+func (r *MT19937) Seed(seed int64) {
+	r.seed(uint32(seed))
+}
+
+// SeedByArray is init_by_array from mt19937ar.c. Python seeds its
+// generator from an integer with its 32-bit words, least significant first.
+// An empty key is the key [0], as Python seeds 0.
+func (r *MT19937) SeedByArray(key []uint32) {
+	if len(key) == 0 {
+		key = []uint32{0}
+	}
+	r.seed(19650218)
+	i, j := 1, 0
+	k := N
+	if len(key) > k {
+		k = len(key)
+	}
+	for ; k > 0; k-- {
+		r.mt[i] = (r.mt[i] ^ (r.mt[i-1]^r.mt[i-1]>>30)*1664525) + key[j] + uint32(j)
+		i++
+		j++
+		if i >= N {
+			r.mt[0] = r.mt[N-1]
+			i = 1
+		}
+		if j >= len(key) {
+			j = 0
+		}
+	}
+	for k = N - 1; k > 0; k-- {
+		r.mt[i] = (r.mt[i] ^ (r.mt[i-1]^r.mt[i-1]>>30)*1566083941) - uint32(i)
+		i++
+		if i >= N {
+			r.mt[0] = r.mt[N-1]
+			i = 1
+		}
+	}
+	r.mt[0] = 0x80000000
+	r.index = N
+}
+
+// SetState replaces the internal state, the next output is computed after
+// a twist as if the state had just been generated.
+func (r *MT19937) SetState(mt []uint32) {
+	copy(r.mt[:], mt)
+	r.index = N
+}
+
+func (r *MT19937) twist() {
+	for i := 0; i < N; i++ {
+		y := (r.mt[i] & upperMask) | (r.mt[(i+1)%N] & lowerMask)
+		x := y >> 1
+		if y&1 != 0 {
+			x ^= matrixA
+		}
+		r.mt[i] = r.mt[(i+M)%N] ^ x
+	}
+	r.index = 0
+}
+
+// Temper is the output transformation applied to a state word.
+func Temper(y uint32) uint32 {
+	y ^= y >> 11
+	y ^= (y << 7) & 0x9d2c5680
+	y ^= (y << 15) & 0xefc60000
+	y ^= y >> 18
+	return y
+}
+
+// Uint32 is genrand_int32.
+func (r *MT19937) Uint32() uint32 {
+	if r.index >= N {
+		r.twist()
+	}
+	y := r.mt[r.index]
+	r.index++
+	return Temper(y)
+}
+
+// Uint64 joins two outputs, the first is the high half.
+func (r *MT19937) Uint64() uint64 {
+	hi := uint64(r.Uint32())
+	return hi<<32 | uint64(r.Uint32())
+}
+
+func (r *MT19937) Int63() int64 {
+	return int64(r.Uint64() >> 1)
+}
+
+// Float64 is genrand_res53, a float in [0, 1) with 53 bits of randomness
+// like Python's random.random().
+func (r *MT19937) Float64() float64 {
+	a := r.Uint32() >> 5
+	b := r.Uint32() >> 6
+	return (float64(a)*67108864 + float64(b)) / 9007199254740992
+}
+
+// MarshalBinary encodes the 624 words of state and the position in it.
+func (r *MT19937) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 4*(N+1))
+	for i, w := range r.mt {
+		binary.BigEndian.PutUint32(b[4*i:], w)
+	}
+	binary.BigEndian.PutUint32(b[4*N:], uint32(r.index))
+	return b, nil
+}
+
+func (r *MT19937) UnmarshalBinary(b []byte) error {
+	if len(b) != 4*(N+1) {
+		return errors.New("mt: invalid state length")
+	}
+	index := int(binary.BigEndian.Uint32(b[4*N:]))
+	if index > N {
+		return errors.New("mt: invalid state index")
+	}
+	for i := range r.mt {
+		r.mt[i] = binary.BigEndian.Uint32(b[4*i:])
+	}
+	r.index = index
+	return nil
+}
@@ -0,0 +1,155 @@
+package mt
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	NN = 312
+	MM = 156
+
+	matrixA64   = 0xb5026f5aa96619e9
+	upperMask64 = 0xffffffff80000000
+	lowerMask64 = 0x7fffffff
+)
+
+// MT64 is the 64-bit Mersenne Twister of mt19937-64.c, the same as C++
+// std::mt19937_64. It implements rand.Source64.
+type MT64 struct {
+	mt    [NN]uint64
+	index int
+}
+
+// New64 returns a generator seeded with init_genrand64(seed).
+func New64(seed uint64) *MT64 {
+	r := &MT64{}
+	r.seed(seed)
+	return r
+}
+
+// NewByArray64 returns a generator seeded with init_by_array64(key).
+func NewByArray64(key []uint64) *MT64 {
+	r := &MT64{}
+	r.SeedByArray(key)
+	return r
+}
+
+func (r *MT64) seed(s uint64) {
+	r.mt[0] = s
+	for i := uint64(1); i < NN; i++ {
+		r.mt[i] = 6364136223846793005*(r.mt[i-1]^r.mt[i-1]>>62) + i
+	}
+	r.index = NN
+}
+
+func (r *MT64) Seed(seed int64) {
+	r.seed(uint64(seed))
+}
+
+// SeedByArray is init_by_array64. An empty key is the key [0].
+func (r *MT64) SeedByArray(key []uint64) {
+	if len(key) == 0 {
+		key = []uint64{0}
+	}
+	r.seed(19650218)
+	i, j := 1, 0
+	k := NN
+	if len(key) > k {
+		k = len(key)
+	}
+	for ; k > 0; k-- {
+		r.mt[i] = (r.mt[i] ^ (r.mt[i-1]^r.mt[i-1]>>62)*3935559000370003845) + key[j] + uint64(j)
+		i++
+		j++
+		if i >= NN {
+			r.mt[0] = r.mt[NN-1]
+			i = 1
+		}
+		if j >= len(key) {
+			j = 0
+		}
+	}
+	for k = NN - 1; k > 0; k-- {
+		r.mt[i] = (r.mt[i] ^ (r.mt[i-1]^r.mt[i-1]>>62)*2862933555777941757) - uint64(i)
+		i++
+		if i >= NN {
+			r.mt[0] = r.mt[NN-1]
+			i = 1
+		}
+	}
+	r.mt[0] = 1 << 63
+	r.index = NN
+}
+
+// SetState replaces the internal state, the next output is computed after
+// a twist.
+func (r *MT64) SetState(mt []uint64) {
+	copy(r.mt[:], mt)
+	r.index = NN
+}
+
+func (r *MT64) twist() {
+	for i := 0; i < NN; i++ {
+		y := (r.mt[i] & upperMask64) | (r.mt[(i+1)%NN] & lowerMask64)
+		x := y >> 1
+		if y&1 != 0 {
+			x ^= matrixA64
+		}
+		r.mt[i] = r.mt[(i+MM)%NN] ^ x
+	}
+	r.index = 0
+}
+
+// Temper64 is the output transformation of the 64-bit generator.
+func Temper64(y uint64) uint64 {
+	y ^= (y >> 29) & 0x5555555555555555
+	y ^= (y << 17) & 0x71d67fffeda60000
+	y ^= (y << 37) & 0xfff7eee000000000
+	y ^= y >> 43
+	return y
+}
+
+// Uint64 is genrand64_int64.
+func (r *MT64) Uint64() uint64 {
+	if r.index >= NN {
+		r.twist()
+	}
+	y := r.mt[r.index]
+	r.index++
+	return Temper64(y)
+}
+
+func (r *MT64) Int63() int64 {
+	return int64(r.Uint64() >> 1)
+}
+
+// Float64 is genrand64_res53, a float in [0, 1).
+func (r *MT64) Float64() float64 {
+	return float64(r.Uint64()>>11) / 9007199254740992
+}
+
+// MarshalBinary encodes the 312 words of state and the position in it.
+func (r *MT64) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 8*(NN+1))
+	for i, w := range r.mt {
+		binary.BigEndian.PutUint64(b[8*i:], w)
+	}
+	binary.BigEndian.PutUint64(b[8*NN:], uint64(r.index))
+	return b, nil
+}
+
+func (r *MT64) UnmarshalBinary(b []byte) error {
+	if len(b) != 8*(NN+1) {
+		return errors.New("mt: invalid state length")
+	}
+	index := binary.BigEndian.Uint64(b[8*NN:])
+	if index > NN {
+		return errors.New("mt: invalid state index")
+	}
+	for i := range r.mt {
+		r.mt[i] = binary.BigEndian.Uint64(b[8*i:])
+	}
+	r.index = int(index)
+	return nil
+}
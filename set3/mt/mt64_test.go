@@ -0,0 +1,64 @@
+package mt
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var _ rand.Source64 = &MT64{}
+
+func TestMT64(t *testing.T) {
+	// std::mt19937_64 with the default seed, the standard requires the
+	// 10000th output to be 9981545732273789042
+	exp := []uint64{14514284786278117030, 4620546740167642908, 13109570281517897720}
+	r := New64(5489)
+	for i := 0; i < 10000; i++ {
+		n := r.Uint64()
+		if i < len(exp) && n != exp[i] {
+			t.Fatalf("output %d: expected %d, got %d", i, exp[i], n)
+		}
+		if i == 9999 && n != 9981545732273789042 {
+			t.Fatalf("unexpected 10000th output %d", n)
+		}
+	}
+}
+
+func TestByArray64(t *testing.T) {
+	// mt19937-64.out
+	first := []uint64{7266447313870364031, 4946485549665804864, 16945909448695747420,
+		16394063075524226720, 4873882236456199058}
+	last := []uint64{10197035660403006684, 13004818533162292132, 9831652587047067687,
+		7619315254749630976, 994412663058993407}
+	r := NewByArray64([]uint64{0x12345, 0x23456, 0x34567, 0x45678})
+	for i := 0; i < 1000; i++ {
+		n := r.Uint64()
+		if i < len(first) && n != first[i] {
+			t.Fatalf("output %d: expected %d, got %d", i, first[i], n)
+		}
+		if j := i - (1000 - len(last)); j >= 0 && n != last[j] {
+			t.Fatalf("output %d: expected %d, got %d", i, last[j], n)
+		}
+	}
+}
+
+func TestEmptyArray64(t *testing.T) {
+	exp := NewByArray64([]uint64{0}).Uint64()
+	if n := NewByArray64(nil).Uint64(); n != exp {
+		t.Fatalf("expected %d, got %d", exp, n)
+	}
+}
+
+func TestMarshal64(t *testing.T) {
+	r := New64(42)
+	r.Uint64()
+	b, _ := r.MarshalBinary()
+	c := &MT64{}
+	if err := c.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if x, y := r.Uint64(), c.Uint64(); x != y {
+			t.Fatalf("output %d: %d != %d", i, x, y)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package mt
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// first and last of the 1000 outputs in mt19937ar.out
+var (
+	arrayKey   = []uint32{0x123, 0x234, 0x345, 0x456}
+	arrayFirst = []uint32{1067595299, 955945823, 477289528, 4107218783, 4228976476,
+		3344332714, 3355579695, 227628506, 810200273, 2591290167}
+	arrayLast = []uint32{2643151863, 3896204135, 2416995901, 1397735321, 3460025646}
+)
+
+var _ rand.Source64 = &MT19937{}
+
+func TestByArray(t *testing.T) {
+	r := NewByArray(arrayKey)
+	for i := 0; i < 1000; i++ {
+		n := r.Uint32()
+		if i < len(arrayFirst) && n != arrayFirst[i] {
+			t.Fatalf("output %d: expected %d, got %d", i, arrayFirst[i], n)
+		}
+		if j := i - (1000 - len(arrayLast)); j >= 0 && n != arrayLast[j] {
+			t.Fatalf("output %d: expected %d, got %d", i, arrayLast[j], n)
+		}
+	}
+}
+
+func TestSeed(t *testing.T) {
+	// default seed of mt19937ar.c and std::mt19937
+	if n := New(5489).Uint32(); n != 3499211612 {
+		t.Fatalf("unexpected first output %d", n)
+	}
+	// Python's random.seed(5489)
+	if n := NewByArray([]uint32{5489}).Uint32(); n != 3382763572 {
+		t.Fatalf("unexpected first output %d", n)
+	}
+	// Python's random.seed(0) uses the key [0]
+	for _, key := range [][]uint32{nil, {}} {
+		if n := NewByArray(key).Uint32(); n != 3626764237 {
+			t.Fatalf("unexpected first output %d for key %v", n, key)
+		}
+	}
+}
+
+func TestFloat64(t *testing.T) {
+	// random.random() after seeding Python with the mt19937ar.out key
+	exp := []float64{0.24856890158782508, 0.11112762955044497, 0.9846353141863877}
+	r := NewByArray(arrayKey)
+	for i, f := range exp {
+		if x := r.Float64(); x != f {
+			t.Fatalf("float %d: expected %v, got %v", i, f, x)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	r := New(42)
+	for i := 0; i < 1000; i++ {
+		r.Uint32()
+	}
+	b, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &MT19937{}
+	if err := c.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if x, y := r.Uint32(), c.Uint32(); x != y {
+			t.Fatalf("output %d: %d != %d", i, x, y)
+		}
+	}
+	if err := c.UnmarshalBinary(b[1:]); err == nil {
+		t.Fatal("short state accepted")
+	}
+	b2, _ := c.MarshalBinary()
+	b, _ = r.MarshalBinary()
+	if bytes.Compare(b, b2) != 0 {
+		t.Fatal("states differ")
+	}
+}
+
+func TestSetState(t *testing.T) {
+	r := New(1)
+	r.Uint32()
+	state := make([]uint32, N)
+	for i := range state {
+		state[i] = 1
+	}
+	// the index must restart even if the generator was in use
+	r.SetState(state)
+	c := &MT19937{}
+	c.SetState(state)
+	for i := 0; i < N+10; i++ {
+		if x, y := r.Uint32(), c.Uint32(); x != y {
+			t.Fatalf("output %d: %d != %d", i, x, y)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package mt
+
+import "errors"
+
+var (
+	errNoSeed = errors.New("mt: state does not come from init_genrand")
+	errNoKey  = errors.New("mt: state does not come from init_by_array")
+)
+
+// State returns a copy of the current state words.
+func (r *MT19937) State() []uint32 {
+	s := make([]uint32, N)
+	copy(s, r.mt[:])
+	return s
+}
+
+// untwistWord inverts x = y>>1 ^ (y&1)*matrixA: as the top bit of
+// matrixA is set, the top bit of x is the low bit of y.
+func untwistWord(x uint32) uint32 {
+	if x&upperMask != 0 {
+		return (x^matrixA)<<1 | 1
+	}
+	return x << 1
+}
+
+// Untwist returns the state before a twist. The low 31 bits of the first
+// word never affect the outputs, they cannot be recovered and are zero.
+func Untwist(state []uint32) []uint32 {
+	s := make([]uint32, N)
+	// the last N-M words were mixed with words already twisted
+	for i := N - 1; i >= N-M; i-- {
+		y := untwistWord(state[i] ^ state[i-(N-M)])
+		s[i] |= y & upperMask
+		if i+1 < N {
+			s[i+1] |= y & lowerMask
+		}
+	}
+	for i := N - M - 1; i >= 0; i-- {
+		y := untwistWord(state[i] ^ s[i+M])
+		s[i] |= y & upperMask
+		s[i+1] |= y & lowerMask
+	}
+	return s
+}
+
+// unshift30 inverts x ^= x >> 30
+func unshift30(x uint32) uint32 {
+	return x ^ x>>30
+}
+
+// inverse returns the inverse of an odd number modulo 2^32
+func inverse(a uint32) uint32 {
+	// Newton iteration, each step doubles the correct low bits
+	x := a
+	for i := 0; i < 5; i++ {
+		x *= 2 - a*x
+	}
+	return x
+}
+
+func sameState(r *MT19937, state []uint32) bool {
+	r.twist()
+	for i := range state {
+		if r.mt[i] != state[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RecoverSeed finds the seed of init_genrand from the state after the
+// first twist, like the state cloned from the first N outputs.
+func RecoverSeed(state []uint32) (uint32, error) {
+	s := Untwist(state)
+	// s[1] = 1812433253*(s[0]^s[0]>>30) + 1
+	seed := unshift30((s[1] - 1) * inverse(1812433253))
+	if !sameState(New(seed), state) {
+		return 0, errNoSeed
+	}
+	return seed, nil
+}
+
+// RecoverKey finds the key of init_by_array, up to N-3 words long, from the
+// state after the first twist.
+func RecoverKey(state []uint32) ([]uint32, error) {
+	s := Untwist(state)
+	// undo the second loop of init_by_array, which ends on word 1 with
+	// word 0 set to the last word
+	f := make([]uint32, N)
+	copy(f, s)
+	f[1] = (s[1] + 1) ^ (s[N-1]^s[N-1]>>30)*1566083941
+	for i := N - 1; i >= 2; i-- {
+		f[i] = (s[i] + uint32(i)) ^ (f[i-1]^f[i-1]>>30)*1566083941
+	}
+	// in the first loop, step t >= 2 sets word t+1 from the initial value
+	// and word t, adding key[t%len(key)] + t%len(key)
+	g := New(19650218)
+	sums := make([]uint32, N-1)
+	for t := 2; t < N-1; t++ {
+		sums[t] = f[t+1] - (g.mt[t+1] ^ (f[t]^f[t]>>30)*1664525)
+	}
+	for l := 1; l <= N-3; l++ {
+		key := make([]uint32, l)
+		ok := true
+		for t := 2; t < N-1 && ok; t++ {
+			j := t % l
+			k := sums[t] - uint32(j)
+			if t < 2+l {
+				key[j] = k
+			} else {
+				ok = key[j] == k
+			}
+		}
+		if ok && sameState(NewByArray(key), state) {
+			return key, nil
+		}
+	}
+	return nil, errNoKey
+}
@@ -0,0 +1,72 @@
+package mt
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// firstState returns the state cloned from the first N outputs
+func firstState(t *testing.T, r *MT19937) []uint32 {
+	outputs := make([]uint32, N)
+	for i := range outputs {
+		outputs[i] = r.Uint32()
+	}
+	c, err := Clone(outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.State()
+}
+
+func TestUntwist(t *testing.T) {
+	r := New(rand.Uint32())
+	before := r.State()
+	r.twist()
+	s := Untwist(r.State())
+	for i := 1; i < N; i++ {
+		if s[i] != before[i] {
+			t.Fatalf("word %d: expected %x, got %x", i, before[i], s[i])
+		}
+	}
+	if s[0]&upperMask != before[0]&upperMask {
+		t.Fatal("wrong top bit of word 0")
+	}
+}
+
+func TestRecoverSeed(t *testing.T) {
+	seed := rand.Uint32()
+	got, err := RecoverSeed(firstState(t, New(seed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != seed {
+		t.Fatalf("expected seed %d, got %d", seed, got)
+	}
+	if _, err := RecoverSeed(firstState(t, NewByArray([]uint32{seed}))); err == nil {
+		t.Fatal("seed found for init_by_array")
+	}
+}
+
+func TestRecoverKey(t *testing.T) {
+	for _, l := range []int{1, 2, 4, 100} {
+		key := make([]uint32, l)
+		for i := range key {
+			key[i] = rand.Uint32()
+		}
+		got, err := RecoverKey(firstState(t, NewByArray(key)))
+		if err != nil {
+			t.Fatalf("key of %d words: %v", l, err)
+		}
+		if len(got) != l {
+			t.Fatalf("expected key of %d words, got %d", l, len(got))
+		}
+		for i := range key {
+			if got[i] != key[i] {
+				t.Fatalf("word %d: expected %x, got %x", i, key[i], got[i])
+			}
+		}
+	}
+	if _, err := RecoverKey(firstState(t, New(1))); err == nil {
+		t.Fatal("key found for init_genrand")
+	}
+}
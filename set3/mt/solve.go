@@ -0,0 +1,231 @@
+package mt
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+// Unknowns is the number of bits of the state. Only 19937 of them affect
+// the outputs: the low 31 bits of the first word are never used.
+const Unknowns = N * 32
+
+const words = Unknowns / 64
+
+// bitvec is a linear combination of the bits of the state
+type bitvec []uint64
+
+func unit(i int) bitvec {
+	v := make(bitvec, words)
+	v[i/64] = 1 << uint(i%64)
+	return v
+}
+
+func (v bitvec) xor(w bitvec) {
+	for i := range v {
+		v[i] ^= w[i]
+	}
+}
+
+func (v bitvec) copy() bitvec {
+	w := make(bitvec, words)
+	copy(w, v)
+	return w
+}
+
+// observation is the value of the bits in mask of the n-th output
+type observation struct {
+	n           int
+	value, mask uint32
+}
+
+// Solver recovers the state of a generator from any bits of its outputs,
+// writing each output bit as a linear equation over GF(2) in the bits of
+// the state. The outputs are counted from a point where the generator is
+// about to twist, like after seeding or SetState.
+type Solver struct {
+	obs    []observation
+	pivots []bitvec // pivots[i] has lowest bit i
+	rhs    []uint64
+	rank   int
+}
+
+func NewSolver() *Solver {
+	return &Solver{
+		pivots: make([]bitvec, Unknowns),
+		rhs:    make([]uint64, Unknowns),
+	}
+}
+
+var errNegative = errors.New("mt: negative output index")
+
+// Observe records the bits of value in mask as the bits of output n.
+func (s *Solver) Observe(n int, value, mask uint32) error {
+	if n < 0 {
+		return errNegative
+	}
+	s.obs = append(s.obs, observation{n, value & mask, mask})
+	return nil
+}
+
+// ObserveBits records a k bits value taken from the top of output n, as
+// Python's getrandbits(k) for k <= 32.
+func (s *Solver) ObserveBits(n int, value uint32, k uint) error {
+	return s.Observe(n, value<<(32-k), ^uint32(0)<<(32-k))
+}
+
+// ObserveFloat records a float from Float64, which uses outputs n and n+1.
+func (s *Solver) ObserveFloat(n int, f float64) error {
+	x := uint64(f * 9007199254740992)
+	if err := s.ObserveBits(n, uint32(x>>26), 27); err != nil {
+		return err
+	}
+	return s.ObserveBits(n+1, uint32(x&(1<<26-1)), 26)
+}
+
+// Rank is the number of independent equations collected by Solve.
+func (s *Solver) Rank() int {
+	return s.rank
+}
+
+// Rank of the outputs of a generator: the bits never used are free.
+const fullRank = Unknowns - 31
+
+var errInconsistent = errors.New("mt: observations are not consistent")
+
+// ErrUnderdetermined is returned by Solve when the observations leave some
+// bits of the state free, so that no clone is reliable.
+var ErrUnderdetermined = errors.New("mt: not enough observations to determine the state")
+
+// add reduces an equation by the pivots and keeps it if independent
+func (s *Solver) add(v bitvec, rhs uint64) error {
+	for w := 0; w < words; w++ {
+		for v[w] != 0 {
+			i := w*64 + bits.TrailingZeros64(v[w])
+			if s.pivots[i] == nil {
+				s.pivots[i] = v
+				s.rhs[i] = rhs
+				s.rank++
+				return nil
+			}
+			v.xor(s.pivots[i])
+			rhs ^= s.rhs[i]
+		}
+	}
+	if rhs != 0 {
+		return errInconsistent
+	}
+	return nil
+}
+
+// tempering[k] is the set of state bits whose sum is bit k of the output
+var tempering [32]uint32
+
+func init() {
+	for j := uint(0); j < 32; j++ {
+		t := Temper(1 << j)
+		for k := uint(0); k < 32; k++ {
+			if t&(1<<k) != 0 {
+				tempering[k] |= 1 << j
+			}
+		}
+	}
+}
+
+// symbolic is the state of the generator as combinations of the unknowns
+type symbolic [N][32]bitvec
+
+func newSymbolic() *symbolic {
+	st := &symbolic{}
+	for i := range st {
+		for j := range st[i] {
+			st[i][j] = unit(i*32 + j)
+		}
+	}
+	return st
+}
+
+// twist mirrors MT19937.twist, in place.
+func (st *symbolic) twist() {
+	for i := 0; i < N; i++ {
+		next := st[(i+1)%N]
+		var w [32]bitvec
+		for j := 0; j < 32; j++ {
+			v := st[(i+M)%N][j].copy()
+			// y >> 1: bit 31 of y is from mt[i], the others from mt[i+1]
+			switch {
+			case j == 30:
+				v.xor(st[i][31])
+			case j < 30:
+				v.xor(next[j+1])
+			}
+			if matrixA&(1<<uint(j)) != 0 {
+				v.xor(next[0])
+			}
+			w[j] = v
+		}
+		st[i] = w
+	}
+}
+
+func (st *symbolic) output(word int, bit uint) bitvec {
+	v := make(bitvec, words)
+	t := tempering[bit]
+	for t != 0 {
+		j := bits.TrailingZeros32(t)
+		v.xor(st[word][j])
+		t &= t - 1
+	}
+	return v
+}
+
+// Solve finds the state consistent with the observations and returns a
+// generator that produces output 0 next. The observations must determine
+// all the 19937 bits that affect the outputs.
+func (s *Solver) Solve() (*MT19937, error) {
+	sort.Slice(s.obs, func(i, j int) bool { return s.obs[i].n < s.obs[j].n })
+	st := newSymbolic()
+	round := -1
+	for _, o := range s.obs {
+		for round < o.n/N {
+			st.twist()
+			round++
+		}
+		for b := uint(0); b < 32; b++ {
+			if o.mask&(1<<b) == 0 {
+				continue
+			}
+			rhs := uint64(o.value>>b) & 1
+			if err := s.add(st.output(o.n%N, b), rhs); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if s.rank < fullRank {
+		return nil, fmt.Errorf("%w: rank %d of %d", ErrUnderdetermined, s.rank, fullRank)
+	}
+	// back substitution, the unused bits are zero
+	x := make(bitvec, words)
+	for i := Unknowns - 1; i >= 0; i-- {
+		p := s.pivots[i]
+		if p == nil {
+			continue
+		}
+		b := s.rhs[i]
+		for w := i / 64; w < words; w++ {
+			b ^= uint64(bits.OnesCount64(p[w] & x[w]))
+		}
+		// the pivot bit itself is still zero in x
+		if b&1 != 0 {
+			x[i/64] |= 1 << uint(i%64)
+		}
+	}
+	state := make([]uint32, N)
+	for i := range state {
+		state[i] = uint32(x[i/2] >> uint(32*(i%2)))
+	}
+	r := &MT19937{}
+	r.SetState(state)
+	return r, nil
+}
@@ -0,0 +1,94 @@
+package mt
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func checkClone(t *testing.T, r, c *MT19937, skip int) {
+	for i := 0; i < skip; i++ {
+		c.Uint32()
+	}
+	for i := 0; i < 1000; i++ {
+		if x, y := r.Uint32(), c.Uint32(); x != y {
+			t.Fatalf("output %d: %d != %d", skip+i, x, y)
+		}
+	}
+}
+
+func TestSolveBits(t *testing.T) {
+	r := New(rand.Uint32())
+	s := NewSolver()
+	// getrandbits(8)
+	n := 2600
+	for i := 0; i < n; i++ {
+		if err := s.ObserveBits(i, r.Uint32()>>24, 8); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := s.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Rank() != fullRank {
+		t.Fatalf("unexpected rank %d", s.Rank())
+	}
+	checkClone(t, r, c, n)
+}
+
+func TestSolveFloats(t *testing.T) {
+	r := NewByArray([]uint32{rand.Uint32()})
+	s := NewSolver()
+	// random.random() and every other output hidden but its top bit
+	n := 0
+	for n < 2000 {
+		if err := s.ObserveFloat(n, r.Float64()); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Observe(n+2, r.Uint32(), 1<<31); err != nil {
+			t.Fatal(err)
+		}
+		n += 3
+	}
+	c, err := s.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClone(t, r, c, n)
+}
+
+func TestSolveInconsistent(t *testing.T) {
+	s := NewSolver()
+	s.Observe(0, 0, 1)
+	s.Observe(0, 1, 1)
+	if _, err := s.Solve(); err == nil {
+		t.Fatal("inconsistent observations solved")
+	}
+}
+
+func TestSolveUnderdetermined(t *testing.T) {
+	r := New(rand.Uint32())
+	s := NewSolver()
+	for i := 0; i < 1000; i++ {
+		if err := s.ObserveBits(i, r.Uint32()>>24, 8); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Solve(); !errors.Is(err, ErrUnderdetermined) {
+		t.Fatalf("expected ErrUnderdetermined, got %v", err)
+	}
+	if s.Rank() != 8000 {
+		t.Fatalf("unexpected rank %d", s.Rank())
+	}
+}
+
+func TestObserveNegative(t *testing.T) {
+	s := NewSolver()
+	if err := s.ObserveFloat(-1, 0.5); err == nil {
+		t.Fatal("negative output index accepted")
+	}
+	if len(s.obs) != 0 {
+		t.Fatalf("%d observations recorded", len(s.obs))
+	}
+}
@@ -0,0 +1,141 @@
+package prng
+
+import "math/rand"
+
+const (
+	additiveLen = 607
+	additiveTap = 273
+)
+
+// Additive is the additive lagged Fibonacci generator of Go's math/rand,
+// x[n] = x[n-607] + x[n-273] mod 2^64. It implements rand.Source64.
+type Additive struct {
+	vec       [additiveLen]uint64
+	tap, feed int
+}
+
+// cooked is xored to the seeded state by math/rand. Its values are the
+// state of the generator after many iterations: rather than copying them,
+// they are taken from math/rand itself by running it backwards.
+var cooked [additiveLen]uint64
+
+func init() {
+	src := rand.NewSource(1).(rand.Source64)
+	// x[i] is output i-additiveLen, the state before the first output
+	// comes first
+	var x [2 * additiveLen]uint64
+	for i := additiveLen; i < len(x); i++ {
+		x[i] = src.Uint64()
+	}
+	for i := len(x) - 1; i >= additiveLen; i-- {
+		x[i-additiveLen] = x[i] - x[i-additiveTap]
+	}
+	seeded := seedVec(1)
+	for i := range cooked {
+		// word i of the state is output -((i+274) mod 607)
+		k := (i + additiveTap + 1) % additiveLen
+		if k == 0 {
+			k = additiveLen
+		}
+		cooked[i] = x[additiveLen-k] ^ seeded[i]
+	}
+}
+
+// seedrand is x[n+1] = 48271 * x[n] % (2^31 - 1)
+func seedrand(x int32) int32 {
+	const (
+		a = 48271
+		q = 44488
+		r = 3399
+	)
+	hi, lo := x/q, x%q
+	x = a*lo - r*hi
+	if x < 0 {
+		x += 1<<31 - 1
+	}
+	return x
+}
+
+// seedVec is the state from seed before xoring the cooked values
+func seedVec(seed int64) [additiveLen]uint64 {
+	var vec [additiveLen]uint64
+	seed = seed % (1<<31 - 1)
+	if seed < 0 {
+		seed += 1<<31 - 1
+	}
+	if seed == 0 {
+		seed = 89482311
+	}
+	x := int32(seed)
+	for i := -20; i < additiveLen; i++ {
+		x = seedrand(x)
+		if i >= 0 {
+			u := uint64(x) << 40
+			x = seedrand(x)
+			u ^= uint64(x) << 20
+			x = seedrand(x)
+			u ^= uint64(x)
+			vec[i] = u
+		}
+	}
+	return vec
+}
+
+// NewAdditive is rand.NewSource(seed).
+func NewAdditive(seed int64) *Additive {
+	r := &Additive{}
+	r.Seed(seed)
+	return r
+}
+
+func (r *Additive) Seed(seed int64) {
+	r.tap = 0
+	r.feed = additiveLen - additiveTap
+	r.vec = seedVec(seed)
+	for i := range r.vec {
+		r.vec[i] ^= cooked[i]
+	}
+}
+
+func (r *Additive) Uint64() uint64 {
+	r.tap--
+	if r.tap < 0 {
+		r.tap += additiveLen
+	}
+	r.feed--
+	if r.feed < 0 {
+		r.feed += additiveLen
+	}
+	x := r.vec[r.feed] + r.vec[r.tap]
+	r.vec[r.feed] = x
+	return x
+}
+
+func (r *Additive) Int63() int64 {
+	return int64(r.Uint64() &^ (1 << 63))
+}
+
+func (r *Additive) Output() uint64 {
+	return uint64(r.Int63())
+}
+
+// AdditiveCloner clones math/rand from 607 outputs of Int63. The top bits
+// of the state are lost but they never reach the low 63 bits, so the
+// clone's Int63 outputs are right and only the top bit of Uint64 may not.
+type AdditiveCloner struct{}
+
+func (AdditiveCloner) Outputs() int {
+	return additiveLen
+}
+
+func (AdditiveCloner) Clone(outputs []uint64) (Generator, error) {
+	if len(outputs) < additiveLen {
+		return nil, errOutputs
+	}
+	// output j of a freshly seeded generator goes to word feed-1-j
+	r := &Additive{tap: 0, feed: additiveLen - additiveTap}
+	for j := 0; j < additiveLen; j++ {
+		r.vec[(r.feed-1-j+additiveLen)%additiveLen] = outputs[j]
+	}
+	return skip(r, outputs[additiveLen:])
+}
@@ -0,0 +1,31 @@
+package prng
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestAdditive(t *testing.T) {
+	for _, seed := range []int64{0, 1, -5, 1 << 40, 7436529318374856135} {
+		src := rand.NewSource(seed).(rand.Source64)
+		r := NewAdditive(seed)
+		for i := 0; i < 2000; i++ {
+			if x, y := src.Uint64(), r.Uint64(); x != y {
+				t.Fatalf("seed %d output %d: expected %d, got %d", seed, i, x, y)
+			}
+		}
+	}
+}
+
+// source shows the Int63 outputs of math/rand
+type source struct {
+	rand.Source
+}
+
+func (s source) Output() uint64 {
+	return uint64(s.Int63())
+}
+
+func TestAdditiveClone(t *testing.T) {
+	testClone(t, source{rand.NewSource(1)}, AdditiveCloner{})
+}
@@ -0,0 +1,140 @@
+package prng
+
+import "math/bits"
+
+// Glibc is rand() from glibc with the default TYPE_3 state: an additive
+// generator r[i] = r[i-3] + r[i-31] that drops the low bit of each word.
+type Glibc struct {
+	r [31]uint32 // r[p] is r[i-31]
+	p int
+}
+
+// NewGlibc is srand(seed).
+func NewGlibc(seed uint32) *Glibc {
+	g := &Glibc{}
+	g.Seed(seed)
+	return g
+}
+
+// Seed fills the first words with a Lehmer generator and discards the
+// first 310 outputs, like srandom_r.
+func (g *Glibc) Seed(seed uint32) {
+	if seed == 0 {
+		seed = 1
+	}
+	var r [34]int32
+	r[0] = int32(seed)
+	word := int64(r[0])
+	for i := 1; i < 31; i++ {
+		// 16807 * word % 2147483647 without overflow
+		hi, lo := word/127773, word%127773
+		word = 16807*lo - 2836*hi
+		if word < 0 {
+			word += 2147483647
+		}
+		r[i] = int32(word)
+	}
+	for i := 31; i < 34; i++ {
+		r[i] = r[i-31]
+	}
+	for i := 3; i < 34; i++ {
+		g.r[i%31] = uint32(r[i])
+	}
+	g.p = 34 % 31
+	for i := 34; i < 344; i++ {
+		g.next()
+	}
+}
+
+func (g *Glibc) next() uint32 {
+	x := g.r[g.p] + g.r[(g.p+28)%31]
+	g.r[g.p] = x
+	g.p = (g.p + 1) % 31
+	return x
+}
+
+// Rand is rand(), a number in [0, 2^31).
+func (g *Glibc) Rand() int32 {
+	return int32(g.next() >> 1)
+}
+
+func (g *Glibc) Output() uint64 {
+	return uint64(g.Rand())
+}
+
+// GlibcCloner recovers the dropped low bits. They follow the linear
+// recurrence l[i] = l[i-3] ^ l[i-31] and output i is one more than the sum
+// of outputs i-3 and i-31 only when both low bits are set: each such carry
+// gives two equations in the first 31 low bits. About 300 outputs are
+// usually enough to find all of them: when the carries leave some low bits
+// free, Clone fails with errOutputs and must be called again with more
+// outputs.
+type GlibcCloner struct{}
+
+func (GlibcCloner) Outputs() int {
+	return 400
+}
+
+func (GlibcCloner) Clone(outputs []uint64) (Generator, error) {
+	n := len(outputs)
+	if n < 31 {
+		return nil, errOutputs
+	}
+	// low[i] is the set of the first 31 low bits whose sum is l[i]
+	low := make([]uint32, n)
+	for i := 0; i < 31; i++ {
+		low[i] = 1 << uint(i)
+	}
+	var (
+		pivots [31]uint32
+		rhs    [31]uint32
+		rank   int
+	)
+	add := func(v, b uint32) bool {
+		for i := uint(0); i < 31; i++ {
+			if v&(1<<i) == 0 {
+				continue
+			}
+			if pivots[i] == 0 {
+				pivots[i], rhs[i] = v, b
+				rank++
+				return true
+			}
+			v ^= pivots[i]
+			b ^= rhs[i]
+		}
+		return b == 0
+	}
+	for i := 31; i < n; i++ {
+		low[i] = low[i-3] ^ low[i-31]
+		sum := (outputs[i-3] + outputs[i-31]) & 0x7fffffff
+		switch outputs[i] {
+		case sum:
+		case (sum + 1) & 0x7fffffff:
+			if !add(low[i-3], 1) || !add(low[i-31], 1) {
+				return nil, errNoState
+			}
+		default:
+			return nil, errNoState
+		}
+	}
+	if rank < 31 {
+		return nil, errOutputs
+	}
+	// back substitution
+	var x uint32
+	for i := 30; i >= 0; i-- {
+		b := rhs[i]
+		for v := pivots[i] &^ (1 << uint(i)); v != 0; v &= v - 1 {
+			b ^= x >> uint(bits.TrailingZeros32(v)) & 1
+		}
+		x |= b << uint(i)
+	}
+	g := &Glibc{}
+	for i := n - 31; i < n; i++ {
+		l := uint32(bits.OnesCount32(low[i]&x)) & 1
+		g.r[i%31] = uint32(outputs[i])<<1 | l
+	}
+	g.p = n % 31
+	return g, nil
+}
@@ -0,0 +1,53 @@
+package prng
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGlibc(t *testing.T) {
+	// from glibc srand(seed) and rand()
+	tests := []struct {
+		seed uint32
+		out  []int32
+	}{
+		{0, []int32{1804289383, 846930886, 1681692777, 1714636915, 1957747793}},
+		{1, []int32{1804289383, 846930886, 1681692777, 1714636915, 1957747793}},
+		{42, []int32{71876166, 708592740, 1483128881, 907283241, 442951012}},
+		{3735928559, []int32{352217057, 918588210, 499345174, 513054021, 248820349}},
+	}
+	for _, tt := range tests {
+		g := NewGlibc(tt.seed)
+		for i, exp := range tt.out {
+			if n := g.Rand(); n != exp {
+				t.Fatalf("seed %d output %d: expected %d, got %d", tt.seed, i, exp, n)
+			}
+		}
+	}
+	g := NewGlibc(1)
+	for i := 0; i < 999; i++ {
+		g.Rand()
+	}
+	if n := g.Rand(); n != 1143565421 {
+		t.Fatalf("expected 1143565421 as output 1000, got %d", n)
+	}
+}
+
+func TestGlibcClone(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		testClone(t, NewGlibc(rnd.Uint32()), GlibcCloner{})
+	}
+}
+
+func TestGlibcCloneFew(t *testing.T) {
+	// 40 outputs give at most 18 equations on the 31 low bits
+	g := NewGlibc(42)
+	outputs := make([]uint64, 40)
+	for i := range outputs {
+		outputs[i] = g.Output()
+	}
+	if _, err := (GlibcCloner{}).Clone(outputs); err != errOutputs {
+		t.Fatalf("expected %v, got %v", errOutputs, err)
+	}
+}
@@ -0,0 +1,151 @@
+package prng
+
+const (
+	javaMult = 0x5deece66d
+	javaAdd  = 0xb
+	javaMask = 1<<48 - 1
+)
+
+// Java is java.util.Random, a 48 bits LCG that outputs the top bits of
+// its state.
+type Java struct {
+	seed uint64
+	// Bits is the width of the values returned by Output, 32 as for
+	// nextInt() if zero.
+	Bits uint
+}
+
+// NewJava is new Random(seed).
+func NewJava(seed int64) *Java {
+	r := &Java{}
+	r.SetSeed(seed)
+	return r
+}
+
+// SetSeed is setSeed, the seed is scrambled before use.
+func (r *Java) SetSeed(seed int64) {
+	r.seed = (uint64(seed) ^ javaMult) & javaMask
+}
+
+// SetState sets the internal 48 bits state, without scrambling.
+func (r *Java) SetState(state uint64) {
+	r.seed = state & javaMask
+}
+
+// Next is next(bits), the top bits of the next state.
+func (r *Java) Next(bits uint) int32 {
+	r.seed = (r.seed*javaMult + javaAdd) & javaMask
+	return int32(r.seed >> (48 - bits))
+}
+
+// NextInt is nextInt().
+func (r *Java) NextInt() int32 {
+	return r.Next(32)
+}
+
+// NextIntn is nextInt(bound), bound must be positive.
+func (r *Java) NextIntn(bound int32) int32 {
+	n := r.Next(31)
+	m := bound - 1
+	if bound&m == 0 {
+		return int32((int64(bound) * int64(n)) >> 31)
+	}
+	for u := n; ; u = r.Next(31) {
+		n = u % bound
+		if u-n+m >= 0 {
+			return n
+		}
+	}
+}
+
+// NextDouble is nextDouble(), 53 bits from two outputs.
+func (r *Java) NextDouble() float64 {
+	return float64(int64(r.Next(26))<<27+int64(r.Next(27))) / (1 << 53)
+}
+
+func (r *Java) bits() uint {
+	if r.Bits == 0 {
+		return 32
+	}
+	return r.Bits
+}
+
+func (r *Java) Output() uint64 {
+	return uint64(uint32(r.Next(r.bits())))
+}
+
+// JavaCloner recovers java.util.Random from outputs of next(Bits), like
+// nextInt() for 32 bits or nextInt(2^Bits) for fewer bits. Up to 20 missing
+// bits are brute forced, more with CrackLCG.
+type JavaCloner struct {
+	Bits uint
+}
+
+func (c JavaCloner) bits() uint {
+	if c.Bits == 0 {
+		return 32
+	}
+	return c.Bits
+}
+
+func (c JavaCloner) Outputs() int {
+	b := int(c.bits())
+	if 48-b <= 20 {
+		return 48/b + 2
+	}
+	// the lattice needs some margin over the 48 unknown bits
+	return 96/b + 4
+}
+
+func (c JavaCloner) Clone(outputs []uint64) (Generator, error) {
+	bits := c.bits()
+	if len(outputs) < 2 {
+		return nil, errOutputs
+	}
+	// the outputs are truncated states, mask the sign of next(32)
+	ys := make([]uint64, len(outputs))
+	for i, o := range outputs {
+		ys[i] = o & (1<<bits - 1)
+	}
+	shift := 48 - bits
+	var (
+		state uint64
+		err   error
+	)
+	if shift <= 20 {
+		state, err = bruteLCG(javaMult, javaAdd, javaMask, shift, ys)
+	} else {
+		state, err = CrackLCG(javaMult, javaAdd, 1<<48, shift, ys)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r := &Java{Bits: bits}
+	r.SetState(state)
+	// state is the one that produced the first output
+	for range outputs[1:] {
+		r.Next(bits)
+	}
+	return r, nil
+}
+
+// bruteLCG tries all the missing low bits of the first state for an LCG
+// modulo a power of two, mask is the modulus minus one.
+func bruteLCG(a, c, mask uint64, shift uint, outputs []uint64) (uint64, error) {
+	for low := uint64(0); low < 1<<shift; low++ {
+		x := outputs[0]<<shift | low
+		s := x
+		ok := true
+		for _, y := range outputs[1:] {
+			s = (s*a + c) & mask
+			if s>>shift != y {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return x, nil
+		}
+	}
+	return 0, errNoState
+}
@@ -0,0 +1,55 @@
+package prng
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestJava(t *testing.T) {
+	// from new Random(seed).nextInt()
+	tests := []struct {
+		seed int64
+		out  []int32
+	}{
+		{0, []int32{-1155484576, -723955400, 1033096058, -1690734402}},
+		{42, []int32{-1170105035, 234785527}},
+	}
+	for _, tt := range tests {
+		r := NewJava(tt.seed)
+		for i, exp := range tt.out {
+			if n := r.NextInt(); n != exp {
+				t.Fatalf("seed %d output %d: expected %d, got %d", tt.seed, i, exp, n)
+			}
+		}
+	}
+}
+
+func TestJavaClone(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, bits := range []uint{32, 28, 16, 8} {
+		for i := 0; i < 5; i++ {
+			r := NewJava(rnd.Int63())
+			r.Bits = bits
+			testClone(t, r, JavaCloner{Bits: bits})
+		}
+	}
+}
+
+func TestCrackLCG(t *testing.T) {
+	// 31 bits LCG of the C standard's example, showing the top 8 bits
+	const a, c, m = 1103515245, 12345, 1 << 31
+	x := uint64(rand.New(rand.NewSource(1)).Int63n(m))
+	outputs := make([]uint64, 16)
+	s := x
+	for i := range outputs {
+		outputs[i] = s >> 23
+		s = (a*s + c) % m
+	}
+	got, err := CrackLCG(a, c, m, 23, outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != x {
+		t.Fatalf("expected state %d, got %d", x, got)
+	}
+}
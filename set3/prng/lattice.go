@@ -0,0 +1,75 @@
+package prng
+
+import (
+	"math/big"
+
+	"github.com/dullgiulio/cryptopals-challenge/set8/lattice"
+)
+
+// CrackLCG finds the state x[0] of the generator x[i+1] = a*x[i] + c mod m
+// from the top bits of consecutive states, outputs[i] = x[i] >> shift.
+//
+// With h[i] the outputs shifted back and z[i] the missing low bits,
+// z[i] - a^i*z[0] is a known d[i] modulo m. The vectors v with v[i] = a^i*v[0]
+// mod m are a lattice and z minus (0, d[1], ...) is one of its vectors, so
+// a short z is found as the lattice vector closest to -(0, d[1], ...). It
+// takes more outputs the fewer bits each one has.
+func CrackLCG(a, c, m uint64, shift uint, outputs []uint64) (uint64, error) {
+	n := len(outputs)
+	if n < 2 {
+		return 0, errOutputs
+	}
+	if shift == 0 {
+		return outputs[0], nil
+	}
+	bm := new(big.Int).SetUint64(m)
+	ba := new(big.Int).SetUint64(a)
+	bc := new(big.Int).SetUint64(c)
+	h := make([]*big.Int, n)
+	for i, y := range outputs {
+		h[i] = new(big.Int).Lsh(new(big.Int).SetUint64(y), shift)
+	}
+	// x[i] = ai*x[0] + ci
+	ai, ci := big.NewInt(1), big.NewInt(0)
+	b := make([]lattice.Vector, n)
+	for i := range b {
+		b[i] = make(lattice.Vector, n)
+		for j := range b[i] {
+			b[i][j] = new(big.Rat)
+		}
+	}
+	half := new(big.Int).Lsh(big.NewInt(1), shift-1)
+	w := make(lattice.Vector, n)
+	w[0] = new(big.Rat).SetInt(half)
+	b[0][0].SetInt64(1)
+	for i := 1; i < n; i++ {
+		ai.Mod(ai.Mul(ai, ba), bm)
+		ci.Mod(ci.Add(ci.Mul(ci, ba), bc), bm)
+		b[0][i].SetInt(ai)
+		b[i][i].SetInt(bm)
+		d := new(big.Int).Mul(ai, h[0])
+		d.Add(d, ci).Sub(d, h[i]).Mod(d, bm)
+		// aim at the middle of the range of z[i]
+		w[i] = new(big.Rat).SetInt(d.Sub(half, d))
+	}
+	lattice.LLL(b, big.NewRat(3, 4))
+	v := lattice.Closest(b, w)
+	// the lattice vectors have integer coordinates
+	x := new(big.Int).Add(h[0], v[0].Num())
+	x.Mod(x, bm)
+	if !x.IsUint64() {
+		return 0, errNoState
+	}
+	x0 := x.Uint64()
+	// check all the outputs
+	s := new(big.Int).Set(x)
+	for i, y := range outputs {
+		if i > 0 {
+			s.Mod(s.Add(s.Mul(s, ba), bc), bm)
+		}
+		if new(big.Int).Rsh(s, shift).Uint64() != y {
+			return 0, errNoState
+		}
+	}
+	return x0, nil
+}
@@ -0,0 +1,71 @@
+// Package prng implements non-cryptographic generators met in the wild and
+// recovers their state from observed outputs.
+package prng
+
+import (
+	"errors"
+
+	"github.com/dullgiulio/cryptopals-challenge/set3/mt"
+)
+
+var (
+	errOutputs = errors.New("prng: not enough outputs")
+	errNoState = errors.New("prng: no state produces the outputs")
+)
+
+// Generator is a generator seen from the outside: Output returns the next
+// value that an attacker gets to observe.
+type Generator interface {
+	Output() uint64
+}
+
+// Cloner recovers a generator from its outputs.
+type Cloner interface {
+	// Outputs is the number of consecutive outputs that Clone needs.
+	Outputs() int
+	// Clone returns a generator whose next output follows the last of
+	// the outputs.
+	Clone(outputs []uint64) (Generator, error)
+}
+
+// MT19937 gives the 32 bits outputs of the Mersenne Twister.
+type MT19937 struct {
+	*mt.MT19937
+}
+
+func (r MT19937) Output() uint64 {
+	return uint64(r.Uint32())
+}
+
+// MTCloner clones the Mersenne Twister, untempering N outputs that start
+// right after a twist.
+type MTCloner struct{}
+
+func (MTCloner) Outputs() int {
+	return mt.N
+}
+
+func (MTCloner) Clone(outputs []uint64) (Generator, error) {
+	if len(outputs) < mt.N {
+		return nil, errOutputs
+	}
+	words := make([]uint32, mt.N)
+	for i := range words {
+		words[i] = uint32(outputs[i])
+	}
+	r, err := mt.Clone(words)
+	if err != nil {
+		return nil, err
+	}
+	return skip(MT19937{r}, outputs[mt.N:])
+}
+
+// skip runs g past the outputs, checking that they match
+func skip(g Generator, outputs []uint64) (Generator, error) {
+	for _, o := range outputs {
+		if g.Output() != o {
+			return nil, errNoState
+		}
+	}
+	return g, nil
+}
@@ -0,0 +1,30 @@
+package prng
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/dullgiulio/cryptopals-challenge/set3/mt"
+)
+
+// testClone observes the outputs that c needs from g and checks that the
+// clone continues them.
+func testClone(t *testing.T, g Generator, c Cloner) {
+	outputs := make([]uint64, c.Outputs())
+	for i := range outputs {
+		outputs[i] = g.Output()
+	}
+	clone, err := c.Clone(outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if x, y := g.Output(), clone.Output(); x != y {
+			t.Fatalf("output %d: expected %d, got %d", i, x, y)
+		}
+	}
+}
+
+func TestMTClone(t *testing.T) {
+	testClone(t, MT19937{mt.New(rand.New(rand.NewSource(1)).Uint32())}, MTCloner{})
+}
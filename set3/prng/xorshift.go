@@ -0,0 +1,157 @@
+package prng
+
+import "math/bits"
+
+// XorShift128 is the xorshift128+ generator of V8, where Math.random()
+// only uses the top 52 bits of the first word of the state. V8 fills a
+// cache of 64 numbers and serves it backwards: the outputs here are in the
+// order they are generated.
+type XorShift128 struct {
+	s0, s1 uint64
+}
+
+// murmur is the finalizer of MurmurHash3 that V8 uses to expand the seed
+func murmur(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// NewXorShift128 seeds like V8's RandomNumberGenerator::SetSeed.
+func NewXorShift128(seed int64) *XorShift128 {
+	x := &XorShift128{s0: murmur(uint64(seed))}
+	x.s1 = murmur(^x.s0)
+	return x
+}
+
+// SetState sets both words of the state.
+func (x *XorShift128) SetState(s0, s1 uint64) {
+	x.s0, x.s1 = s0, s1
+}
+
+func (x *XorShift128) step() {
+	s1, s0 := x.s0, x.s1
+	x.s0 = s0
+	s1 ^= s1 << 23
+	s1 ^= s1 >> 17
+	s1 ^= s0
+	s1 ^= s0 >> 26
+	x.s1 = s1
+}
+
+// Uint64 is the xorshift128+ output, the sum of the two words.
+func (x *XorShift128) Uint64() uint64 {
+	x.step()
+	return x.s0 + x.s1
+}
+
+// Output returns the 52 bits of the next Math.random().
+func (x *XorShift128) Output() uint64 {
+	x.step()
+	return x.s0 >> 12
+}
+
+// Float64 is Math.random(), a number in [0, 1).
+func (x *XorShift128) Float64() float64 {
+	return float64(x.Output()) / (1 << 52)
+}
+
+// XorShiftCloner recovers V8's state from the bits of Math.random(),
+// like uint64(f * (1 << 52)) for a value f. Without the sum the generator
+// is linear over GF(2), each output gives 52 equations in the 128 bits of
+// the state. Three outputs are not independent enough, four are.
+type XorShiftCloner struct{}
+
+func (XorShiftCloner) Outputs() int {
+	return 4
+}
+
+// word128 is a linear combination of the 128 bits of the initial state
+type word128 [2]uint64
+
+func (w word128) xor(v word128) word128 {
+	return word128{w[0] ^ v[0], w[1] ^ v[1]}
+}
+
+// symWord is a word of the state as combinations of the initial bits
+type symWord [64]word128
+
+func (w symWord) xor(v symWord) symWord {
+	for i := range w {
+		w[i] = w[i].xor(v[i])
+	}
+	return w
+}
+
+func (w symWord) shl(n uint) symWord {
+	var r symWord
+	for i := n; i < 64; i++ {
+		r[i] = w[i-n]
+	}
+	return r
+}
+
+func (w symWord) shr(n uint) symWord {
+	var r symWord
+	for i := uint(0); i+n < 64; i++ {
+		r[i] = w[i+n]
+	}
+	return r
+}
+
+func (XorShiftCloner) Clone(outputs []uint64) (Generator, error) {
+	var s0, s1 symWord
+	for i := uint(0); i < 64; i++ {
+		s0[i][0] = 1 << i
+		s1[i][1] = 1 << i
+	}
+	var (
+		pivots [128]word128
+		rhs    [128]uint64
+		rank   int
+	)
+	for _, o := range outputs {
+		// the same as step
+		t1, t0 := s0, s1
+		s0 = t0
+		t1 = t1.xor(t1.shl(23))
+		t1 = t1.xor(t1.shr(17))
+		t1 = t1.xor(t0)
+		s1 = t1.xor(t0.shr(26))
+		for b := uint(12); b < 64; b++ {
+			v, r := s0[b], o>>(b-12)&1
+			for v != (word128{}) {
+				i := bits.TrailingZeros64(v[0])
+				if v[0] == 0 {
+					i = 64 + bits.TrailingZeros64(v[1])
+				}
+				if pivots[i] == (word128{}) {
+					pivots[i], rhs[i] = v, r
+					rank++
+					break
+				}
+				v = v.xor(pivots[i])
+				r ^= rhs[i]
+			}
+			if v == (word128{}) && r != 0 {
+				return nil, errNoState
+			}
+		}
+	}
+	if rank < 128 {
+		return nil, errOutputs
+	}
+	// back substitution
+	var x word128
+	for i := 127; i >= 0; i-- {
+		p := pivots[i]
+		r := rhs[i]
+		r ^= uint64(bits.OnesCount64(p[0]&x[0])+bits.OnesCount64(p[1]&x[1])) & 1
+		x[i/64] |= r << uint(i%64)
+	}
+	g := &XorShift128{x[0], x[1]}
+	return skip(g, outputs)
+}
@@ -0,0 +1,34 @@
+package prng
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestXorShiftClone(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		x := NewXorShift128(rnd.Int63())
+		testClone(t, x, XorShiftCloner{})
+	}
+	// from the floats of Math.random()
+	x := NewXorShift128(rnd.Int63())
+	outputs := make([]uint64, 4)
+	for i := range outputs {
+		outputs[i] = uint64(x.Float64() * (1 << 52))
+	}
+	c, err := XorShiftCloner{}.Clone(outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := c.(*XorShift128)
+	for i := 0; i < 100; i++ {
+		if f, g := x.Float64(), clone.Float64(); f != g {
+			t.Fatalf("output %d: expected %v, got %v", i, f, g)
+		}
+	}
+	// the clone has the whole state, not just the bits of the outputs
+	if a, b := x.Uint64(), clone.Uint64(); a != b {
+		t.Fatalf("expected %d, got %d", a, b)
+	}
+}
@@ -0,0 +1,67 @@
+package randtest
+
+import "math"
+
+// igamc is the regularized upper incomplete gamma function Q(a, x), with
+// the series for small x and the continued fraction otherwise.
+func igamc(a, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	if x < a+1 {
+		return 1 - gammaSeries(a, x)
+	}
+	return gammaFraction(a, x)
+}
+
+const (
+	gammaEps  = 1e-15
+	gammaIter = 1000
+)
+
+func lgamma(a float64) float64 {
+	l, _ := math.Lgamma(a)
+	return l
+}
+
+// gammaSeries is P(a, x)
+func gammaSeries(a, x float64) float64 {
+	sum := 1 / a
+	del := sum
+	for n := 1; n < gammaIter; n++ {
+		del *= x / (a + float64(n))
+		sum += del
+		if math.Abs(del) < math.Abs(sum)*gammaEps {
+			break
+		}
+	}
+	return sum * math.Exp(-x+a*math.Log(x)-lgamma(a))
+}
+
+// gammaFraction is Q(a, x) with Lentz's method
+func gammaFraction(a, x float64) float64 {
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < gammaIter; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < gammaEps {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lgamma(a)) * h
+}
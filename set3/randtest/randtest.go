@@ -0,0 +1,286 @@
+// Package randtest implements statistical tests of randomness from NIST
+// SP 800-22. Each test returns a p-value: under 0.01 the sequence is
+// rejected as not random. Passing them says nothing about predictability.
+package randtest
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// Bits splits bytes in bits, most significant first.
+func Bits(data []byte) []byte {
+	b := make([]byte, 0, 8*len(data))
+	for _, c := range data {
+		for j := uint(0); j < 8; j++ {
+			b = append(b, c>>(7-j)&1)
+		}
+	}
+	return b
+}
+
+func ones(b []byte) int {
+	n := 0
+	for _, x := range b {
+		n += int(x)
+	}
+	return n
+}
+
+// Frequency is the monobit test: ones and zeros are about as many.
+func Frequency(b []byte) float64 {
+	s := float64(2*ones(b) - len(b))
+	return math.Erfc(math.Abs(s) / math.Sqrt(float64(len(b))) / math.Sqrt2)
+}
+
+// BlockFrequency is the frequency test within blocks of m bits.
+func BlockFrequency(b []byte, m int) float64 {
+	n := len(b) / m
+	var chi2 float64
+	for i := 0; i < n; i++ {
+		pi := float64(ones(b[i*m:(i+1)*m]))/float64(m) - 0.5
+		chi2 += pi * pi
+	}
+	chi2 *= 4 * float64(m)
+	return igamc(float64(n)/2, chi2/2)
+}
+
+// Runs tests the number of runs of equal bits.
+func Runs(b []byte) float64 {
+	n := float64(len(b))
+	pi := float64(ones(b)) / n
+	// the frequency test would fail anyway
+	if math.Abs(pi-0.5) >= 2/math.Sqrt(n) {
+		return 0
+	}
+	v := 1
+	for i := 1; i < len(b); i++ {
+		if b[i] != b[i-1] {
+			v++
+		}
+	}
+	x := math.Abs(float64(v) - 2*n*pi*(1-pi))
+	return math.Erfc(x / (2 * math.Sqrt(2*n) * pi * (1 - pi)))
+}
+
+// patterns counts the overlapping m bits patterns, wrapping around
+func patterns(b []byte, m int) []int {
+	counts := make([]int, 1<<uint(m))
+	if m == 0 {
+		counts[0] = len(b)
+		return counts
+	}
+	n := len(b)
+	var w int
+	for i := 0; i < m-1; i++ {
+		w = w<<1 | int(b[i])
+	}
+	mask := 1<<uint(m) - 1
+	for i := 0; i < n; i++ {
+		w = (w<<1 | int(b[(i+m-1)%n])) & mask
+		counts[w]++
+	}
+	return counts
+}
+
+func psi2(b []byte, m int) float64 {
+	if m <= 0 {
+		return 0
+	}
+	var s float64
+	for _, c := range patterns(b, m) {
+		s += float64(c) * float64(c)
+	}
+	n := float64(len(b))
+	return s*float64(uint(1)<<uint(m))/n - n
+}
+
+// Serial tests the frequency of all the overlapping m bits patterns, it
+// returns the p-values of the first and second differences.
+func Serial(b []byte, m int) (float64, float64) {
+	p0, p1, p2 := psi2(b, m), psi2(b, m-1), psi2(b, m-2)
+	d1 := p0 - p1
+	d2 := p0 - 2*p1 + p2
+	return igamc(math.Pow(2, float64(m-2)), d1/2), igamc(math.Pow(2, float64(m-3)), d2/2)
+}
+
+func phi(b []byte, m int) float64 {
+	n := float64(len(b))
+	var s float64
+	for _, c := range patterns(b, m) {
+		if c > 0 {
+			p := float64(c) / n
+			s += p * math.Log(p)
+		}
+	}
+	return s
+}
+
+// ApproximateEntropy compares the frequency of patterns of m and m+1 bits.
+func ApproximateEntropy(b []byte, m int) float64 {
+	apen := phi(b, m) - phi(b, m+1)
+	chi2 := 2 * float64(len(b)) * (math.Ln2 - apen)
+	return igamc(math.Pow(2, float64(m-1)), chi2/2)
+}
+
+// rank is the rank over GF(2) of the 32x32 matrix with rows
+func rank(rows [32]uint32) int {
+	r := 0
+	for col := uint(0); col < 32; col++ {
+		bit := uint32(1) << (31 - col)
+		p := -1
+		for i := r; i < 32; i++ {
+			if rows[i]&bit != 0 {
+				p = i
+				break
+			}
+		}
+		if p < 0 {
+			continue
+		}
+		rows[r], rows[p] = rows[p], rows[r]
+		for i := range rows {
+			if i != r && rows[i]&bit != 0 {
+				rows[i] ^= rows[r]
+			}
+		}
+		r++
+	}
+	return r
+}
+
+// Rank tests the rank of disjoint 32x32 matrices.
+func Rank(b []byte) float64 {
+	n := len(b) / 1024
+	var full, less int
+	for k := 0; k < n; k++ {
+		var rows [32]uint32
+		for i := range rows {
+			for j := 0; j < 32; j++ {
+				rows[i] = rows[i]<<1 | uint32(b[k*1024+i*32+j])
+			}
+		}
+		switch rank(rows) {
+		case 32:
+			full++
+		case 31:
+			less++
+		}
+	}
+	N := float64(n)
+	f32, f31 := float64(full), float64(less)
+	rest := N - f32 - f31
+	chi2 := (f32-0.2888*N)*(f32-0.2888*N)/(0.2888*N) +
+		(f31-0.5776*N)*(f31-0.5776*N)/(0.5776*N) +
+		(rest-0.1336*N)*(rest-0.1336*N)/(0.1336*N)
+	return math.Exp(-chi2 / 2)
+}
+
+// BerlekampMassey returns the linear complexity L of b, the length of the
+// shortest LFSR generating it, and its connection polynomial c, where c[j]
+// is the coefficient of x^j: b[n] = c[1]*b[n-1] + ... + c[L]*b[n-L] for
+// n >= L. 2L bits are enough to find them.
+func BerlekampMassey(b []byte) (int, []byte) {
+	c := make([]byte, len(b)+1)
+	p := make([]byte, len(b)+1)
+	t := make([]byte, len(b)+1)
+	c[0], p[0] = 1, 1
+	l, m := 0, 1
+	for n := range b {
+		d := b[n]
+		for i := 1; i <= l; i++ {
+			d ^= c[i] & b[n-i]
+		}
+		if d == 0 {
+			m++
+			continue
+		}
+		copy(t, c)
+		for i := 0; i+m < len(c); i++ {
+			c[i+m] ^= p[i]
+		}
+		if 2*l <= n {
+			l = n + 1 - l
+			p, t = t, p
+			m = 1
+		} else {
+			m++
+		}
+	}
+	return l, c[:l+1]
+}
+
+// LinearComplexity tests the linear complexity of blocks of m bits.
+func LinearComplexity(b []byte, m int) float64 {
+	pis := []float64{0.010417, 0.03125, 0.125, 0.5, 0.25, 0.0625, 0.020833}
+	M := float64(m)
+	sign := 1.0
+	if m%2 == 1 {
+		sign = -1
+	}
+	mu := M/2 + (9-sign)/36 - (M/3+2.0/9)/math.Pow(2, M)
+	n := len(b) / m
+	v := make([]float64, len(pis))
+	for i := 0; i < n; i++ {
+		l, _ := BerlekampMassey(b[i*m : (i+1)*m])
+		t := sign*(float64(l)-mu) + 2.0/9
+		switch {
+		case t <= -2.5:
+			v[0]++
+		case t <= -1.5:
+			v[1]++
+		case t <= -0.5:
+			v[2]++
+		case t <= 0.5:
+			v[3]++
+		case t <= 1.5:
+			v[4]++
+		case t <= 2.5:
+			v[5]++
+		default:
+			v[6]++
+		}
+	}
+	var chi2 float64
+	for i, pi := range pis {
+		e := float64(n) * pi
+		chi2 += (v[i] - e) * (v[i] - e) / e
+	}
+	return igamc(float64(len(pis)-1)/2, chi2/2)
+}
+
+// Result is the p-value of a test.
+type Result struct {
+	Name string
+	P    float64
+}
+
+// Run runs all the tests with the parameters of the NIST suite, data
+// should be at least 2^17 bytes.
+func Run(data []byte) []Result {
+	b := Bits(data)
+	s1, s2 := Serial(b, 16)
+	return []Result{
+		{"frequency", Frequency(b)},
+		{"block frequency", BlockFrequency(b, 128)},
+		{"runs", Runs(b)},
+		{"serial", s1},
+		{"serial 2", s2},
+		{"approximate entropy", ApproximateEntropy(b, 10)},
+		{"matrix rank", Rank(b)},
+		{"linear complexity", LinearComplexity(b, 500)},
+	}
+}
+
+// Report writes the results of Run on data.
+func Report(w io.Writer, name string, data []byte) {
+	fmt.Fprintf(w, "%s:\n", name)
+	for _, r := range Run(data) {
+		verdict := "pass"
+		if r.P < 0.01 {
+			verdict = "FAIL"
+		}
+		fmt.Fprintf(w, "  %-20s %.6f %s\n", r.Name, r.P, verdict)
+	}
+}
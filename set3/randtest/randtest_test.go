@@ -0,0 +1,67 @@
+package randtest
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func parse(s string) []byte {
+	b := make([]byte, len(s))
+	for i := range s {
+		b[i] = s[i] - '0'
+	}
+	return b
+}
+
+func checkP(t *testing.T, name string, p, exp float64) {
+	if math.Abs(p-exp) > 1e-6 {
+		t.Fatalf("%s: expected p-value %f, got %f", name, exp, p)
+	}
+}
+
+// the examples in NIST SP 800-22
+func TestExamples(t *testing.T) {
+	checkP(t, "frequency", Frequency(parse("1011010101")), 0.527089)
+	checkP(t, "block frequency", BlockFrequency(parse("0110011010"), 3), 0.801252)
+	checkP(t, "runs", Runs(parse("1001101011")), 0.147232)
+	p1, p2 := Serial(parse("0011011101"), 3)
+	checkP(t, "serial", p1, 0.808792)
+	checkP(t, "serial 2", p2, 0.670320)
+	checkP(t, "approximate entropy", ApproximateEntropy(parse("0100110101"), 3), 0.261961)
+	s := parse("1101011110001")
+	l, c := BerlekampMassey(s)
+	if l != 4 {
+		t.Fatalf("expected linear complexity 4, got %d", l)
+	}
+	for n := l; n < len(s); n++ {
+		var x byte
+		for i := 1; i <= l; i++ {
+			x ^= c[i] & s[n-i]
+		}
+		if x != s[n] {
+			t.Fatalf("polynomial %v does not generate bit %d", c, n)
+		}
+	}
+	eps := parse("1100100100001111110110101010001000100001011010001100001000110100110001001100011001100010100010111000")
+	checkP(t, "frequency of 100 bits", Frequency(eps), 0.109599)
+	checkP(t, "block frequency of 100 bits", BlockFrequency(eps, 10), 0.706438)
+	checkP(t, "runs of 100 bits", Runs(eps), 0.500798)
+}
+
+func checkRun(t *testing.T, data []byte, exp []float64) {
+	for i, r := range Run(data) {
+		checkP(t, r.Name, r.P, exp[i])
+	}
+}
+
+func TestRun(t *testing.T) {
+	data := make([]byte, 1<<17)
+	rand.New(rand.NewSource(1)).Read(data)
+	checkRun(t, data, []float64{0.504154, 0.016802, 0.370805, 0.517366, 0.745869, 0.949204, 0.470844, 0.496952})
+	// a counter is balanced, but its patterns are not
+	for i := range data {
+		data[i] = byte(i)
+	}
+	checkRun(t, data, []float64{1, 0, 1, 0, 0, 0, 0, 0.081399})
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"os"
+
+	"github.com/dullgiulio/cryptopals-challenge/set4/md4"
+	"github.com/dullgiulio/cryptopals-challenge/set4/sha1"
+)
+
+// hash-extender -a sha1 -digest 6d5f... -data 'user=foo' -append ';admin=true' -min 1 -max 32
+// hash-extender -a sha512 -digest <base64> -data-format hex -data 75736572 -out json
+
+// algo is a Merkle–Damgård hash whose state can be resumed from a digest
+type algo struct {
+	size      int
+	blockSize int
+	// lenSize is the size of the length at the end of the padding
+	lenSize      int
+	littleEndian bool
+	// resume returns a hash with the state of sum after processed bytes
+	resume func(sum []byte, processed uint64) (hash.Hash, error)
+}
+
+var algos = map[string]algo{
+	"sha1":   {sha1.Size, sha1.BlockSize, 8, false, resumeSHA1},
+	"md4":    {md4.Size, md4.BlockSize, 8, true, resumeMD4},
+	"md5":    {md5.Size, md5.BlockSize, 8, true, resumeMD5},
+	"sha256": {sha256.Size, sha256.BlockSize, 8, false, resumeSHA256},
+	"sha512": {sha512.Size, sha512.BlockSize, 16, false, resumeSHA512},
+}
+
+func resumeSHA1(sum []byte, processed uint64) (hash.Hash, error) {
+	regs, err := sha1.Registers(sum)
+	if err != nil {
+		return nil, err
+	}
+	return sha1.NewFromState(regs, processed), nil
+}
+
+func resumeMD4(sum []byte, processed uint64) (hash.Hash, error) {
+	regs, err := md4.Registers(sum)
+	if err != nil {
+		return nil, err
+	}
+	return md4.NewFromState(regs, processed), nil
+}
+
+// The hashes of the standard library are resumed from a marshaled state:
+// the magic, the registers big-endian, an empty block and the length.
+
+func unmarshal(h hash.Hash, magic string, regs []byte, blockSize int, processed uint64) (hash.Hash, error) {
+	state := append([]byte(magic), regs...)
+	state = append(state, make([]byte, blockSize+8)...)
+	binary.BigEndian.PutUint64(state[len(state)-8:], processed)
+	if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func resumeMD5(sum []byte, processed uint64) (hash.Hash, error) {
+	regs := make([]byte, len(sum))
+	// the digest is little-endian, the state big-endian
+	for i := 0; i < len(sum); i += 4 {
+		binary.BigEndian.PutUint32(regs[i:], binary.LittleEndian.Uint32(sum[i:]))
+	}
+	return unmarshal(md5.New(), "md5\x01", regs, md5.BlockSize, processed)
+}
+
+func resumeSHA256(sum []byte, processed uint64) (hash.Hash, error) {
+	return unmarshal(sha256.New(), "sha\x03", sum, sha256.BlockSize, processed)
+}
+
+func resumeSHA512(sum []byte, processed uint64) (hash.Hash, error) {
+	return unmarshal(sha512.New(), "sha\x07", sum, sha512.BlockSize, processed)
+}
+
+// padding returns the padding of a message of n bytes
+func (a algo) padding(n uint64) []byte {
+	pad := a.blockSize - int((n+uint64(a.lenSize))%uint64(a.blockSize))
+	p := make([]byte, pad+a.lenSize)
+	p[0] = 0x80
+	// the length in bits, only its low 64 bits are ever set
+	if a.littleEndian {
+		binary.LittleEndian.PutUint64(p[pad:], n*8)
+	} else {
+		binary.BigEndian.PutUint64(p[len(p)-8:], n*8)
+	}
+	return p
+}
+
+// extend returns the message data, padded as if it followed a key of keyLen
+// bytes, followed by add, and its digest computed from sum, the digest of
+// the key and data.
+func (a algo) extend(sum, data, add []byte, keyLen int) ([]byte, []byte, error) {
+	if len(sum) != a.size {
+		return nil, nil, fmt.Errorf("digest of %d bytes, expected %d", len(sum), a.size)
+	}
+	n := uint64(keyLen + len(data))
+	glue := a.padding(n)
+	h, err := a.resume(sum, n+uint64(len(glue)))
+	if err != nil {
+		return nil, nil, err
+	}
+	h.Write(add)
+	msg := append(append(append([]byte{}, data...), glue...), add...)
+	return msg, h.Sum(nil), nil
+}
+
+// decodeDigest accepts a digest of size bytes in hex or base64
+func decodeDigest(s string, size int) ([]byte, error) {
+	if b, err := hex.DecodeString(s); err == nil && len(b) == size {
+		return b, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil && len(b) == size {
+			return b, nil
+		}
+	}
+	return nil, errors.New("digest is not hex or base64 of the right size")
+}
+
+func decodeInput(s, format string) ([]byte, error) {
+	switch format {
+	case "raw":
+		return []byte(s), nil
+	case "hex":
+		return hex.DecodeString(s)
+	case "base64":
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return nil, fmt.Errorf("unknown input format %s", format)
+}
+
+// urlEncode escapes all bytes but the unreserved characters of RFC 3986
+func urlEncode(b []byte) string {
+	const unreserved = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~"
+	var out []byte
+	for _, c := range b {
+		ok := false
+		for i := 0; i < len(unreserved); i++ {
+			if unreserved[i] == c {
+				ok = true
+				break
+			}
+		}
+		if ok {
+			out = append(out, c)
+		} else {
+			out = append(out, fmt.Sprintf("%%%02X", c)...)
+		}
+	}
+	return string(out)
+}
+
+type candidate struct {
+	KeyLen  int    `json:"keylen"`
+	Message string `json:"message"`
+	Digest  string `json:"digest"`
+}
+
+func write(w io.Writer, cands []candidate, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cands)
+	case "hex", "url":
+		for _, c := range cands {
+			if _, err := fmt.Fprintf(w, "key length %d\nmessage %s\ndigest %s\n\n", c.KeyLen, c.Message, c.Digest); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown output format %s", format)
+}
+
+func main() {
+	name := flag.String("a", "sha1", "hash: sha1, md4, md5, sha256 or sha512")
+	digest := flag.String("digest", "", "known digest of key || data, in hex or base64")
+	data := flag.String("data", "", "original message")
+	dataFormat := flag.String("data-format", "raw", "format of -data: raw, hex or base64")
+	add := flag.String("append", "", "data to append")
+	addFormat := flag.String("append-format", "raw", "format of -append: raw, hex or base64")
+	minLen := flag.Int("min", 1, "shortest key length to try")
+	maxLen := flag.Int("max", 32, "longest key length to try")
+	out := flag.String("out", "hex", "output format: hex, url or json")
+	flag.Parse()
+
+	if *minLen < 0 || *minLen > *maxLen {
+		log.Fatalf("invalid key lengths: need 0 <= min <= max, got %d and %d", *minLen, *maxLen)
+	}
+	a, ok := algos[*name]
+	if !ok {
+		log.Fatalf("unknown hash %s", *name)
+	}
+	sum, err := decodeDigest(*digest, a.size)
+	if err != nil {
+		log.Fatalf("cannot decode digest: %v", err)
+	}
+	msg, err := decodeInput(*data, *dataFormat)
+	if err != nil {
+		log.Fatalf("cannot decode data: %v", err)
+	}
+	suffix, err := decodeInput(*add, *addFormat)
+	if err != nil {
+		log.Fatalf("cannot decode append data: %v", err)
+	}
+	var cands []candidate
+	for k := *minLen; k <= *maxLen; k++ {
+		forged, fsum, err := a.extend(sum, msg, suffix, k)
+		if err != nil {
+			log.Fatalf("cannot extend: %v", err)
+		}
+		m := hex.EncodeToString(forged)
+		if *out == "url" {
+			m = urlEncode(forged)
+		}
+		cands = append(cands, candidate{k, m, hex.EncodeToString(fsum)})
+	}
+	if err := write(os.Stdout, cands, *out); err != nil {
+		log.Fatalf("cannot write candidates: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"math/rand"
+	"testing"
+
+	"github.com/dullgiulio/cryptopals-challenge/set4/md4"
+	"github.com/dullgiulio/cryptopals-challenge/set4/sha1"
+)
+
+var news = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"md4":    md4.New,
+	"md5":    md5.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+func keyed(name string, key, msg []byte) []byte {
+	h := news[name]()
+	h.Write(key)
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+func TestExtend(t *testing.T) {
+	data := []byte("comment1=cooking%20MCs;userdata=foo;comment2=%20like%20a%20pound%20of%20bacon")
+	add := []byte(";admin=true")
+	for name, a := range algos {
+		for _, n := range []int{0, 1, 16, 47, 48, 63, 64, 100 + rand.Intn(100)} {
+			key := make([]byte, n)
+			rand.Read(key)
+			sum := keyed(name, key, data)
+			msg, forged, err := a.extend(sum, data, add, n)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !bytes.HasPrefix(msg, data) || !bytes.HasSuffix(msg, add) {
+				t.Fatalf("%s: wrong message %q", name, msg)
+			}
+			if exp := keyed(name, key, msg); !bytes.Equal(forged, exp) {
+				t.Fatalf("%s key length %d: expected %x, got %x", name, n, exp, forged)
+			}
+			// with the wrong key length the padding is in the wrong place
+			wmsg, wrong, _ := a.extend(sum, data, add, n+1)
+			if bytes.Equal(wrong, keyed(name, key, wmsg)) {
+				t.Fatalf("%s: wrong key length accepted", name)
+			}
+		}
+	}
+}
+
+func TestDecodeDigest(t *testing.T) {
+	sum := md5.Sum([]byte("x"))
+	for _, s := range []string{"9dd4e461268c8034f5c8564e155c67a6", "ndTkYSaMgDT1yFZOFVxnpg==", "ndTkYSaMgDT1yFZOFVxnpg"} {
+		b, err := decodeDigest(s, md5.Size)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if !bytes.Equal(b, sum[:]) {
+			t.Fatalf("%s: expected %x, got %x", s, sum, b)
+		}
+	}
+	if _, err := decodeDigest("9dd4e461268c8034", md5.Size); err == nil {
+		t.Fatal("short digest accepted")
+	}
+}
+
+func TestURLEncode(t *testing.T) {
+	if s := urlEncode([]byte("a=b;c~\x80\x00")); s != "a%3Db%3Bc~%80%00" {
+		t.Fatalf("got %s", s)
+	}
+}
@@ -0,0 +1,191 @@
+// Package md4 implements MD4 (RFC 1320) as a hash.Hash whose state can be
+// set, for length extension attacks.
+package md4
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+const (
+	// Size is the size of a digest in bytes.
+	Size = 16
+	// BlockSize is the size of a block in bytes.
+	BlockSize = 64
+
+	_Init0 = 0x67452301
+	_Init1 = 0xEFCDAB89
+	_Init2 = 0x98BADCFE
+	_Init3 = 0x10325476
+)
+
+var shift1 = []uint{3, 7, 11, 19}
+var shift2 = []uint{3, 5, 9, 13}
+var shift3 = []uint{3, 9, 11, 15}
+
+var xIndex2 = []uint{0, 4, 8, 12, 1, 5, 9, 13, 2, 6, 10, 14, 3, 7, 11, 15}
+var xIndex3 = []uint{0, 8, 4, 12, 2, 10, 6, 14, 1, 9, 5, 13, 3, 11, 7, 15}
+
+type digest struct {
+	s   [4]uint32
+	x   [BlockSize]byte
+	nx  int
+	len uint64
+}
+
+// New returns an MD4 hash.Hash.
+func New() hash.Hash {
+	d := &digest{}
+	d.Reset()
+	return d
+}
+
+// NewFromState returns a hash that continues from the registers regs after
+// processedLen bytes. It panics if processedLen is not a multiple of
+// BlockSize, as the registers only exist between blocks.
+func NewFromState(regs [4]uint32, processedLen uint64) hash.Hash {
+	if processedLen%BlockSize != 0 {
+		panic("md4: processed length is not a multiple of the block size")
+	}
+	return &digest{s: regs, len: processedLen}
+}
+
+func (d *digest) Reset() {
+	d.s = [4]uint32{_Init0, _Init1, _Init2, _Init3}
+	d.nx = 0
+	d.len = 0
+}
+
+func (d *digest) Size() int {
+	return Size
+}
+
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.len += uint64(n)
+	if d.nx > 0 {
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		if d.nx == BlockSize {
+			block(d, d.x[:])
+			d.nx = 0
+		}
+		p = p[c:]
+	}
+	if len(p) >= BlockSize {
+		m := len(p) &^ (BlockSize - 1)
+		block(d, p[:m])
+		p = p[m:]
+	}
+	if len(p) > 0 {
+		d.nx = copy(d.x[:], p)
+	}
+	return n, nil
+}
+
+// Sum appends the digest to b, without changing the state.
+func (d *digest) Sum(b []byte) []byte {
+	c := *d
+	c.Write(Padding(c.len))
+	out := make([]byte, Size)
+	for i, s := range c.s {
+		binary.LittleEndian.PutUint32(out[4*i:], s)
+	}
+	return append(b, out...)
+}
+
+// Sum returns the MD4 digest of data.
+func Sum(data []byte) [Size]byte {
+	d := New()
+	d.Write(data)
+	var out [Size]byte
+	copy(out[:], d.Sum(nil))
+	return out
+}
+
+// Padding returns the padding appended to a message of n bytes: a one
+// bit, zeros and the length in bits, little-endian.
+func Padding(n uint64) []byte {
+	pad := BlockSize - int((n+8)%BlockSize)
+	p := make([]byte, pad+8)
+	p[0] = 0x80
+	binary.LittleEndian.PutUint64(p[pad:], n*8)
+	return p
+}
+
+// Registers returns the state of the hash after the padded message of a
+// digest.
+func Registers(sum []byte) ([4]uint32, error) {
+	var regs [4]uint32
+	if len(sum) != Size {
+		return regs, errors.New("md4: invalid digest size")
+	}
+	for i := range regs {
+		regs[i] = binary.LittleEndian.Uint32(sum[4*i:])
+	}
+	return regs, nil
+}
+
+func block(dig *digest, p []byte) {
+	a := dig.s[0]
+	b := dig.s[1]
+	c := dig.s[2]
+	d := dig.s[3]
+	var X [16]uint32
+	for len(p) >= BlockSize {
+		aa, bb, cc, dd := a, b, c, d
+
+		j := 0
+		for i := 0; i < 16; i++ {
+			X[i] = uint32(p[j]) | uint32(p[j+1])<<8 | uint32(p[j+2])<<16 | uint32(p[j+3])<<24
+			j += 4
+		}
+
+		// Round 1.
+		for i := uint(0); i < 16; i++ {
+			x := i
+			s := shift1[i%4]
+			f := ((c ^ d) & b) ^ d
+			a += f + X[x]
+			a = a<<s | a>>(32-s)
+			a, b, c, d = d, a, b, c
+		}
+
+		// Round 2.
+		for i := uint(0); i < 16; i++ {
+			x := xIndex2[i]
+			s := shift2[i%4]
+			g := (b & c) | (b & d) | (c & d)
+			a += g + X[x] + 0x5a827999
+			a = a<<s | a>>(32-s)
+			a, b, c, d = d, a, b, c
+		}
+
+		// Round 3.
+		for i := uint(0); i < 16; i++ {
+			x := xIndex3[i]
+			s := shift3[i%4]
+			h := b ^ c ^ d
+			a += h + X[x] + 0x6ed9eba1
+			a = a<<s | a>>(32-s)
+			a, b, c, d = d, a, b, c
+		}
+
+		a += aa
+		b += bb
+		c += cc
+		d += dd
+
+		p = p[BlockSize:]
+	}
+
+	dig.s[0] = a
+	dig.s[1] = b
+	dig.s[2] = c
+	dig.s[3] = d
+}
@@ -0,0 +1,63 @@
+package md4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/rand"
+	"testing"
+)
+
+func TestRFC1320(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"a", "bde52cb31de33e46245e05fbdbd6fb24"},
+		{"abc", "a448017aaf21d8525fc10ae87aa6729d"},
+		{"message digest", "d9130a8164549fe818874806e1c7014b"},
+		{"abcdefghijklmnopqrstuvwxyz", "d79e1c308aa5bbcdeea8ed63df412da9"},
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", "043f8582f241db351ce627e153e7f0e4"},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", "e33b4ddc9c38f2199c3e7b164fcc0536"},
+	}
+	for _, tt := range tests {
+		sum := Sum([]byte(tt.in))
+		if got := hex.EncodeToString(sum[:]); got != tt.out {
+			t.Fatalf("MD4(%q): expected %s, got %s", tt.in, tt.out, got)
+		}
+		// the same one byte at a time
+		h := New()
+		for i := 0; i < len(tt.in); i++ {
+			h.Write([]byte{tt.in[i]})
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.out {
+			t.Fatalf("streaming MD4(%q): expected %s, got %s", tt.in, tt.out, got)
+		}
+	}
+}
+
+func TestNewFromState(t *testing.T) {
+	secret := make([]byte, 1+rand.Intn(100))
+	rand.Read(secret)
+	msg := []byte("message")
+	sum := Sum(append(secret, msg...))
+	regs, err := Registers(sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	glued := append(append(append([]byte{}, secret...), msg...), Padding(uint64(len(secret)+len(msg)))...)
+	h := NewFromState(regs, uint64(len(glued)))
+	h.Write([]byte(";admin=true"))
+	exp := Sum(append(glued, ";admin=true"...))
+	if got := h.Sum(nil); !bytes.Equal(got, exp[:]) {
+		t.Fatalf("expected %x, got %x", exp, got)
+	}
+}
+
+func TestNewFromStatePartial(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("length of a partial block accepted")
+		}
+	}()
+	NewFromState([4]uint32{}, BlockSize+1)
+}
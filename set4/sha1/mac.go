@@ -0,0 +1,17 @@
+package sha1
+
+import "crypto/hmac"
+
+// Authenticate is the secret-prefix MAC SHA1(key || msg), which is open to
+// length extension.
+func Authenticate(key, msg []byte) []byte {
+	h := New()
+	h.Write(key)
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// Verify checks the MAC of msg in constant time.
+func Verify(key, msg, mac []byte) bool {
+	return hmac.Equal(mac, Authenticate(key, msg))
+}
@@ -0,0 +1,57 @@
+package sha1
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"testing"
+)
+
+func TestAuthenticate(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	msg := []byte("comment1=cooking%20MCs;userdata=foo")
+	mac := Authenticate(key, msg)
+	exp := sha1.Sum(append(append([]byte{}, key...), msg...))
+	if !bytes.Equal(mac, exp[:]) {
+		t.Fatalf("expected %x, got %x", exp, mac)
+	}
+	if !Verify(key, msg, mac) {
+		t.Fatal("valid MAC rejected")
+	}
+}
+
+func TestTamper(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	msg := []byte("comment1=cooking%20MCs;userdata=foo")
+	mac := Authenticate(key, msg)
+	for i := range msg {
+		tampered := append([]byte{}, msg...)
+		tampered[i] ^= 0x20
+		if Verify(key, tampered, mac) {
+			t.Fatalf("message changed at byte %d accepted", i)
+		}
+	}
+	if Verify(key, append(msg, ";admin=true"...), mac) {
+		t.Fatal("message with appended data accepted")
+	}
+	bad := append([]byte{}, mac...)
+	bad[0] ^= 1
+	if Verify(key, msg, bad) {
+		t.Fatal("changed MAC accepted")
+	}
+}
+
+func TestForge(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	msg := []byte("comment1=cooking%20MCs;userdata=foo;admin=true")
+	// without the key: hashing the message alone or with another key
+	plain := sha1.Sum(msg)
+	if Verify(key, msg, plain[:]) {
+		t.Fatal("hash of the message accepted as MAC")
+	}
+	if Verify(key, msg, Authenticate([]byte("YELLOW SUBMARINF"), msg)) {
+		t.Fatal("MAC with another key accepted")
+	}
+	if Verify(key, msg, nil) {
+		t.Fatal("empty MAC accepted")
+	}
+}
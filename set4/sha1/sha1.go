@@ -0,0 +1,244 @@
+// Package sha1 implements SHA-1 as a hash.Hash whose state can be exported
+// and set, for length extension attacks.
+package sha1
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+const (
+	// Size is the size of a digest in bytes.
+	Size = 20
+	// BlockSize is the size of a block in bytes.
+	BlockSize = 64
+
+	init0 = 0x67452301
+	init1 = 0xEFCDAB89
+	init2 = 0x98BADCFE
+	init3 = 0x10325476
+	init4 = 0xC3D2E1F0
+
+	_K0 = 0x5A827999
+	_K1 = 0x6ED9EBA1
+	_K2 = 0x8F1BBCDC
+	_K3 = 0xCA62C1D6
+
+	// the marshaled state is compatible with crypto/sha1
+	magic         = "sha\x01"
+	marshaledSize = len(magic) + 5*4 + BlockSize + 8
+)
+
+type digest struct {
+	h   [5]uint32
+	x   [BlockSize]byte
+	nx  int
+	len uint64
+}
+
+// New returns a SHA-1 hash.Hash, which also implements
+// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler.
+func New() hash.Hash {
+	d := &digest{}
+	d.Reset()
+	return d
+}
+
+// NewFromState returns a hash that continues from the registers regs after
+// processedLen bytes. The registers only exist between blocks, so
+// processedLen must be a multiple of BlockSize or the padding written by
+// Sum would be wrong: NewFromState panics if it is not. With the registers
+// of a digest and the length of the padded message, it computes the digest
+// of the message extended with what is written to it.
+func NewFromState(regs [5]uint32, processedLen uint64) hash.Hash {
+	if processedLen%BlockSize != 0 {
+		panic("sha1: processed length is not a multiple of the block size")
+	}
+	return &digest{h: regs, len: processedLen}
+}
+
+func (d *digest) Reset() {
+	d.h = [5]uint32{init0, init1, init2, init3, init4}
+	d.nx = 0
+	d.len = 0
+}
+
+func (d *digest) Size() int {
+	return Size
+}
+
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.len += uint64(n)
+	if d.nx > 0 {
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		if d.nx == BlockSize {
+			block(d, d.x[:])
+			d.nx = 0
+		}
+		p = p[c:]
+	}
+	if len(p) >= BlockSize {
+		m := len(p) &^ (BlockSize - 1)
+		block(d, p[:m])
+		p = p[m:]
+	}
+	if len(p) > 0 {
+		d.nx = copy(d.x[:], p)
+	}
+	return n, nil
+}
+
+// Sum appends the digest to b, without changing the state.
+func (d *digest) Sum(b []byte) []byte {
+	c := *d
+	c.Write(Padding(c.len))
+	out := make([]byte, Size)
+	for i, s := range c.h {
+		binary.BigEndian.PutUint32(out[4*i:], s)
+	}
+	return append(b, out...)
+}
+
+func (d *digest) MarshalBinary() ([]byte, error) {
+	b := make([]byte, marshaledSize)
+	copy(b, magic)
+	p := b[len(magic):]
+	for i, s := range d.h {
+		binary.BigEndian.PutUint32(p[4*i:], s)
+	}
+	copy(p[20:], d.x[:d.nx])
+	binary.BigEndian.PutUint64(p[20+BlockSize:], d.len)
+	return b, nil
+}
+
+func (d *digest) UnmarshalBinary(b []byte) error {
+	if len(b) < len(magic) || string(b[:len(magic)]) != magic {
+		return errors.New("sha1: invalid hash state identifier")
+	}
+	if len(b) != marshaledSize {
+		return errors.New("sha1: invalid hash state size")
+	}
+	b = b[len(magic):]
+	for i := range d.h {
+		d.h[i] = binary.BigEndian.Uint32(b)
+		b = b[4:]
+	}
+	copy(d.x[:], b)
+	d.len = binary.BigEndian.Uint64(b[BlockSize:])
+	d.nx = int(d.len % BlockSize)
+	return nil
+}
+
+// Sum returns the SHA-1 digest of data.
+func Sum(data []byte) [Size]byte {
+	d := New()
+	d.Write(data)
+	var out [Size]byte
+	copy(out[:], d.Sum(nil))
+	return out
+}
+
+// Padding returns the padding appended to a message of n bytes: a one
+// bit, zeros and the length in bits.
+func Padding(n uint64) []byte {
+	pad := BlockSize - int((n+8)%BlockSize)
+	p := make([]byte, pad+8)
+	p[0] = 0x80
+	binary.BigEndian.PutUint64(p[pad:], n*8)
+	return p
+}
+
+// Registers returns the state of the hash after the padded message of a
+// digest.
+func Registers(sum []byte) ([5]uint32, error) {
+	var regs [5]uint32
+	if len(sum) != Size {
+		return regs, errors.New("sha1: invalid digest size")
+	}
+	for i := range regs {
+		regs[i] = binary.BigEndian.Uint32(sum[4*i:])
+	}
+	return regs, nil
+}
+
+func block(dig *digest, p []byte) {
+	var w [16]uint32
+
+	h0, h1, h2, h3, h4 := dig.h[0], dig.h[1], dig.h[2], dig.h[3], dig.h[4]
+	for len(p) >= BlockSize {
+		// Can interlace the computation of w with the
+		// rounds below if needed for speed.
+		for i := 0; i < 16; i++ {
+			j := i * 4
+			w[i] = uint32(p[j])<<24 | uint32(p[j+1])<<16 | uint32(p[j+2])<<8 | uint32(p[j+3])
+		}
+
+		a, b, c, d, e := h0, h1, h2, h3, h4
+
+		// Each of the four 20-iteration rounds
+		// differs only in the computation of f and
+		// the choice of K (_K0, _K1, etc).
+		i := 0
+		for ; i < 16; i++ {
+			f := b&c | (^b)&d
+			a5 := a<<5 | a>>(32-5)
+			b30 := b<<30 | b>>(32-30)
+			t := a5 + f + e + w[i&0xf] + _K0
+			a, b, c, d, e = t, a, b30, c, d
+		}
+		for ; i < 20; i++ {
+			tmp := w[(i-3)&0xf] ^ w[(i-8)&0xf] ^ w[(i-14)&0xf] ^ w[(i)&0xf]
+			w[i&0xf] = tmp<<1 | tmp>>(32-1)
+
+			f := b&c | (^b)&d
+			a5 := a<<5 | a>>(32-5)
+			b30 := b<<30 | b>>(32-30)
+			t := a5 + f + e + w[i&0xf] + _K0
+			a, b, c, d, e = t, a, b30, c, d
+		}
+		for ; i < 40; i++ {
+			tmp := w[(i-3)&0xf] ^ w[(i-8)&0xf] ^ w[(i-14)&0xf] ^ w[(i)&0xf]
+			w[i&0xf] = tmp<<1 | tmp>>(32-1)
+			f := b ^ c ^ d
+			a5 := a<<5 | a>>(32-5)
+			b30 := b<<30 | b>>(32-30)
+			t := a5 + f + e + w[i&0xf] + _K1
+			a, b, c, d, e = t, a, b30, c, d
+		}
+		for ; i < 60; i++ {
+			tmp := w[(i-3)&0xf] ^ w[(i-8)&0xf] ^ w[(i-14)&0xf] ^ w[(i)&0xf]
+			w[i&0xf] = tmp<<1 | tmp>>(32-1)
+			f := ((b | c) & d) | (b & c)
+
+			a5 := a<<5 | a>>(32-5)
+			b30 := b<<30 | b>>(32-30)
+			t := a5 + f + e + w[i&0xf] + _K2
+			a, b, c, d, e = t, a, b30, c, d
+		}
+		for ; i < 80; i++ {
+			tmp := w[(i-3)&0xf] ^ w[(i-8)&0xf] ^ w[(i-14)&0xf] ^ w[(i)&0xf]
+			w[i&0xf] = tmp<<1 | tmp>>(32-1)
+			f := b ^ c ^ d
+			a5 := a<<5 | a>>(32-5)
+			b30 := b<<30 | b>>(32-30)
+			t := a5 + f + e + w[i&0xf] + _K3
+			a, b, c, d, e = t, a, b30, c, d
+		}
+
+		h0 += a
+		h1 += b
+		h2 += c
+		h3 += d
+		h4 += e
+
+		p = p[BlockSize:]
+	}
+	dig.h[0], dig.h[1], dig.h[2], dig.h[3], dig.h[4] = h0, h1, h2, h3, h4
+}
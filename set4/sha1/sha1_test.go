@@ -0,0 +1,102 @@
+package sha1
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding"
+	"math/rand"
+	"testing"
+)
+
+func TestSum(t *testing.T) {
+	for n := 0; n < 300; n++ {
+		data := make([]byte, n)
+		rand.Read(data)
+		if x, y := Sum(data), sha1.Sum(data); x != y {
+			t.Fatalf("length %d: expected %x, got %x", n, y, x)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	data := make([]byte, 10000)
+	rand.Read(data)
+	h := New()
+	for p := data; len(p) > 0; {
+		n := rand.Intn(200)
+		if n > len(p) {
+			n = len(p)
+		}
+		h.Write(p[:n])
+		p = p[n:]
+		// Sum does not change the state
+		h.Sum(nil)
+	}
+	exp := sha1.Sum(data)
+	if got := h.Sum(nil); !bytes.Equal(got, exp[:]) {
+		t.Fatalf("expected %x, got %x", exp, got)
+	}
+	h.Reset()
+	h.Write([]byte("abc"))
+	exp = sha1.Sum([]byte("abc"))
+	if got := h.Sum(nil); !bytes.Equal(got, exp[:]) {
+		t.Fatalf("after reset: expected %x, got %x", exp, got)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	data := make([]byte, 1000)
+	rand.Read(data)
+	for _, n := range []int{0, 1, 63, 64, 65, 500} {
+		h := New()
+		h.Write(data[:n])
+		state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the state moves between this package and crypto/sha1
+		std := sha1.New()
+		if err := std.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+			t.Fatal(err)
+		}
+		std.Write(data[n:])
+		h2 := New()
+		if err := h2.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+			t.Fatal(err)
+		}
+		h2.Write(data[n:])
+		exp := sha1.Sum(data)
+		if got := std.Sum(nil); !bytes.Equal(got, exp[:]) {
+			t.Fatalf("crypto/sha1 from state after %d bytes: expected %x, got %x", n, exp, got)
+		}
+		if got := h2.Sum(nil); !bytes.Equal(got, exp[:]) {
+			t.Fatalf("from state after %d bytes: expected %x, got %x", n, exp, got)
+		}
+	}
+}
+
+func TestNewFromState(t *testing.T) {
+	secret := []byte("secret key")
+	msg := []byte("message")
+	sum := Sum(append(secret, msg...))
+	regs, err := Registers(sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	glued := append(append(append([]byte{}, secret...), msg...), Padding(uint64(len(secret)+len(msg)))...)
+	h := NewFromState(regs, uint64(len(glued)))
+	h.Write([]byte(";admin=true"))
+	exp := sha1.Sum(append(glued, ";admin=true"...))
+	if got := h.Sum(nil); !bytes.Equal(got, exp[:]) {
+		t.Fatalf("expected %x, got %x", exp, got)
+	}
+}
+
+func TestNewFromStatePartial(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("length of a partial block accepted")
+		}
+	}()
+	NewFromState([5]uint32{}, BlockSize+1)
+}
@@ -0,0 +1,58 @@
+// Package dh implements Diffie-Hellman key exchange modulo a prime.
+package dh
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+const nistP = "ffffffffffffffffc90fdaa22168c234c4c6628b80dc1cd129024e088a67cc74020bbea63b139b22514a08798e3404ddef9519b3cd3a431b302b0a6df25f14374fe1356d6d51c245e485b576625e7ec6f44c42e9a637ed6b0bff5cb6f406b7edee386bfb5a899fa5ae9f24117c4b1fe649286651ece45b3dc2007cb8a163bf0598da48361c55d39a69163fa8fd24cf5f83655d23dca3ad961c62f356208552bb9ed529077096966d670c354e4abc9804f1746c08ca237327ffffffffffffffff"
+
+var one = big.NewInt(1)
+
+// Group is the subgroup of order Q generated by G modulo P. Q can be nil
+// when the order is not known, private keys are then taken modulo P.
+type Group struct {
+	P, G, Q *big.Int
+}
+
+// NIST returns the group of the NIST prime with generator 2.
+func NIST() *Group {
+	p, _ := new(big.Int).SetString(nistP, 16)
+	return &Group{P: p, G: big.NewInt(2)}
+}
+
+// Key is a key pair of a group.
+type Key struct {
+	Group   *Group
+	Private *big.Int
+	Public  *big.Int
+}
+
+// GenerateKey returns a key with a random private exponent.
+func (g *Group) GenerateKey() (*Key, error) {
+	max := g.Q
+	if max == nil {
+		max = g.P
+	}
+	x, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return nil, err
+	}
+	return g.NewKey(x), nil
+}
+
+// NewKey returns the key with private exponent x.
+func (g *Group) NewKey(x *big.Int) *Key {
+	return &Key{
+		Group:   g,
+		Private: x,
+		Public:  new(big.Int).Exp(g.G, x, g.P),
+	}
+}
+
+// Shared returns the secret shared with the owner of the public key pub.
+// pub is not validated: in a small subgroup the secret is one of few values.
+func (k *Key) Shared(pub *big.Int) *big.Int {
+	return new(big.Int).Exp(pub, k.Private, k.Group.P)
+}
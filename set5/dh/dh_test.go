@@ -0,0 +1,70 @@
+package dh
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestShared(t *testing.T) {
+	g := NIST()
+	a, err := g.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := g.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Shared(b.Public).Cmp(b.Shared(a.Public)) != 0 {
+		t.Fatal("shared secrets differ")
+	}
+}
+
+func TestNewKey(t *testing.T) {
+	// 2 has order 11 modulo 23
+	g := &Group{P: big.NewInt(23), G: big.NewInt(2), Q: big.NewInt(11)}
+	k := g.NewKey(big.NewInt(5))
+	if k.Public.Int64() != 9 {
+		t.Fatalf("unexpected public key %s", k.Public)
+	}
+	if s := k.Shared(big.NewInt(3)); s.Int64() != 13 {
+		t.Fatalf("unexpected shared secret %s", s)
+	}
+}
+
+// p-1 = q * 2 * 3^2 * 5^2 * 7 * 11 * 13 * 17 and g has order q
+var small = &Group{
+	P: big.NewInt(765787972951),
+	G: big.NewInt(634596791449),
+	Q: big.NewInt(100003),
+}
+
+func TestSubgroupAttack(t *testing.T) {
+	b, err := NewBob(small)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, m, err := SubgroupAttack(small, b.Handle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Cmp(small.Q) <= 0 {
+		t.Fatalf("key only known modulo %s", m)
+	}
+	if x.Cmp(b.Key.Private) != 0 {
+		t.Fatalf("recovered %s, expected %s", x, b.Key.Private)
+	}
+}
+
+func TestSubgroupAttackWrongMAC(t *testing.T) {
+	b, err := NewBob(small)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lie := func(pub *big.Int) ([]byte, []byte) {
+		return b.Msg, MAC(big.NewInt(0), b.Msg)
+	}
+	if _, _, err := SubgroupAttack(small, lie); err == nil {
+		t.Fatal("no error when no key matches the MAC")
+	}
+}
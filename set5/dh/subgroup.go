@@ -0,0 +1,100 @@
+package dh
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/dullgiulio/cryptopals-challenge/set5/dlog"
+)
+
+// MAC is HMAC-SHA256 of msg keyed with the shared secret.
+func MAC(key *big.Int, msg []byte) []byte {
+	h := hmac.New(sha256.New, key.Bytes())
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// Bob answers to a public key with a message MACed with the shared secret,
+// without checking that the public key is in the group.
+type Bob struct {
+	Key *Key
+	Msg []byte
+}
+
+func NewBob(g *Group) (*Bob, error) {
+	key, err := g.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &Bob{
+		Key: key,
+		Msg: []byte("crazy flamboyant for the rap enjoyment"),
+	}, nil
+}
+
+// Handle returns the message and its MAC.
+func (b *Bob) Handle(pub *big.Int) ([]byte, []byte) {
+	return b.Msg, MAC(b.Key.Shared(pub), b.Msg)
+}
+
+// ElementOfOrder finds an element of prime order r in Z_p^*, r must divide
+// p-1.
+func (g *Group) ElementOfOrder(r *big.Int) (*big.Int, error) {
+	p1 := (&big.Int{}).Sub(g.P, one)
+	e := (&big.Int{}).Div(p1, r)
+	h := &big.Int{}
+	for {
+		x, err := rand.Int(rand.Reader, g.P)
+		if err != nil {
+			return nil, err
+		}
+		h.Exp(x, e, g.P)
+		if h.Cmp(one) > 0 {
+			return h, nil
+		}
+	}
+}
+
+// SubgroupAttack recovers the private key of handle modulo the product of
+// the small factors r of (p-1)/q: an element h of order r as public key
+// makes the shared secret h^x, one of r values that the MAC tells apart.
+// It stops when the product exceeds q, as the key is then known.
+func SubgroupAttack(g *Group, handle func(pub *big.Int) ([]byte, []byte)) (*big.Int, *big.Int, error) {
+	j := (&big.Int{}).Sub(g.P, one)
+	j.Div(j, g.Q)
+	var as, ms []*big.Int
+	prod := big.NewInt(1)
+	for _, r := range dlog.SmallFactors(j, 1<<16) {
+		if (&big.Int{}).Mod(g.Q, r).Sign() == 0 {
+			continue
+		}
+		h, err := g.ElementOfOrder(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		msg, mac := handle(h)
+		k := &big.Int{}
+		K := big.NewInt(1)
+		for ; k.Cmp(r) < 0; k.Add(k, one) {
+			if hmac.Equal(MAC(K, msg), mac) {
+				break
+			}
+			K.Mul(K, h)
+			K.Mod(K, g.P)
+		}
+		if k.Cmp(r) == 0 {
+			return nil, nil, fmt.Errorf("dh: no key matches the MAC modulo %s", r)
+		}
+		as = append(as, k)
+		ms = append(ms, r)
+		prod.Mul(prod, r)
+		if prod.Cmp(g.Q) > 0 {
+			break
+		}
+	}
+	x, m := dlog.CRT(as, ms)
+	return x, m, nil
+}
@@ -0,0 +1,20 @@
+package dlog
+
+import "math/big"
+
+// CRT returns x modulo the product of the pairwise coprime moduli ms, given
+// x = as[i] mod ms[i]. The product is returned as second value.
+func CRT(as, ms []*big.Int) (*big.Int, *big.Int) {
+	x := big.NewInt(0)
+	m := big.NewInt(1)
+	for i := range as {
+		// x + m*t = as[i] mod ms[i]
+		t := (&big.Int{}).Sub(as[i], x)
+		inv := (&big.Int{}).ModInverse(m, ms[i])
+		t.Mul(t, inv)
+		t.Mod(t, ms[i])
+		x.Add(x, t.Mul(t, m))
+		m.Mul(m, ms[i])
+	}
+	return x, m
+}
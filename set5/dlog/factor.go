@@ -0,0 +1,22 @@
+package dlog
+
+import "math/big"
+
+// SmallFactors returns the distinct prime factors of n below max, by trial
+// division.
+func SmallFactors(n *big.Int, max int64) []*big.Int {
+	var fs []*big.Int
+	n = (&big.Int{}).Set(n)
+	r, m := &big.Int{}, &big.Int{}
+	for f := int64(2); f < max; f++ {
+		r.SetInt64(f)
+		if m.Mod(n, r).Sign() != 0 {
+			continue
+		}
+		fs = append(fs, big.NewInt(f))
+		for m.Mod(n, r).Sign() == 0 {
+			n.Div(n, r)
+		}
+	}
+	return fs
+}
@@ -0,0 +1,169 @@
+// Package dlog computes discrete logarithms in cyclic groups.
+package dlog
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// ErrNotFound is returned when the logarithm is not in the searched interval.
+var ErrNotFound = errors.New("discrete logarithm not found")
+
+var one = big.NewInt(1)
+
+// Element is a member of a Group.
+type Element interface{}
+
+// Group is a cyclic group written multiplicatively.
+type Group interface {
+	Op(x, y Element) Element
+	Exp(x Element, n *big.Int) Element
+	Equal(x, y Element) bool
+	// Index maps an element to an integer used to choose its jump.
+	Index(x Element) *big.Int
+}
+
+// ModP is the multiplicative group of integers modulo P.
+type ModP struct {
+	P *big.Int
+}
+
+func (m ModP) Op(x, y Element) Element {
+	z := &big.Int{}
+	z.Mul(x.(*big.Int), y.(*big.Int))
+	return z.Mod(z, m.P)
+}
+
+func (m ModP) Exp(x Element, n *big.Int) Element {
+	z := &big.Int{}
+	return z.Exp(x.(*big.Int), n, m.P)
+}
+
+func (m ModP) Equal(x, y Element) bool {
+	return x.(*big.Int).Cmp(y.(*big.Int)) == 0
+}
+
+func (m ModP) Index(x Element) *big.Int {
+	return x.(*big.Int)
+}
+
+// JumpFunc chooses which of the n jump distances is taken from an element.
+type JumpFunc func(index *big.Int, n int) int
+
+// ModJump is the jump function of the original paper, f(y) = jumps[y mod n].
+func ModJump(index *big.Int, n int) int {
+	m := &big.Int{}
+	return int(m.Mod(index, big.NewInt(int64(n))).Int64())
+}
+
+// PowerJumps returns the k jump distances 1, 2, 4, ..., 2^(k-1).
+func PowerJumps(k int) []*big.Int {
+	jumps := make([]*big.Int, k)
+	for i := range jumps {
+		jumps[i] = (&big.Int{}).Lsh(one, uint(i))
+	}
+	return jumps
+}
+
+// MeanJump returns the average of the jump distances.
+func MeanJump(jumps []*big.Int) *big.Int {
+	sum := &big.Int{}
+	for _, j := range jumps {
+		sum.Add(sum, j)
+	}
+	return sum.Div(sum, big.NewInt(int64(len(jumps))))
+}
+
+// TuneK returns the smallest k so that the mean of PowerJumps(k) is at
+// least half the square root of the interval width b - a.
+func TuneK(a, b *big.Int) int {
+	w := (&big.Int{}).Sub(b, a)
+	target := w.Sqrt(w)
+	target.Rsh(target, 1)
+	for k := 1; ; k++ {
+		if MeanJump(PowerJumps(k)).Cmp(target) >= 0 {
+			return k
+		}
+	}
+}
+
+// Stats reports the work done by a search.
+type Stats struct {
+	Tame, Wild int
+	// Expected is sqrt(b - a), the order of jumps the method needs.
+	Expected float64
+}
+
+func (s Stats) String() string {
+	n := s.Tame + s.Wild
+	return fmt.Sprintf("%d tame + %d wild = %d jumps, %.2f x sqrt(b-a)", s.Tame, s.Wild, n, float64(n)/s.Expected)
+}
+
+// Kangaroo implements Pollard's lambda method.
+type Kangaroo struct {
+	Group Group
+	Jumps []*big.Int
+	// Choose defaults to ModJump.
+	Choose JumpFunc
+	// Tame is the number of jumps of the tame kangaroo, defaults to
+	// four times the mean jump.
+	Tame int
+}
+
+// NewKangaroo returns a search tuned for the interval [a, b] using TuneK.
+func NewKangaroo(g Group, a, b *big.Int) *Kangaroo {
+	return &Kangaroo{
+		Group: g,
+		Jumps: PowerJumps(TuneK(a, b)),
+	}
+}
+
+func (k *Kangaroo) tame() int {
+	if k.Tame > 0 {
+		return k.Tame
+	}
+	return int(4 * MeanJump(k.Jumps).Int64())
+}
+
+// Search finds x in [a, b] such that g^x = y.
+func (k *Kangaroo) Search(g, y Element, a, b *big.Int) (*big.Int, Stats, error) {
+	choose := k.Choose
+	if choose == nil {
+		choose = ModJump
+	}
+	w := (&big.Int{}).Sub(b, a)
+	wf, _ := new(big.Float).SetInt(w).Float64()
+	stats := Stats{Expected: math.Sqrt(wf)}
+
+	gs := make([]Element, len(k.Jumps))
+	for i, j := range k.Jumps {
+		gs[i] = k.Group.Exp(g, j)
+	}
+
+	xT := &big.Int{}
+	yT := k.Group.Exp(g, b)
+	n := k.tame()
+	for i := 0; i < n; i++ {
+		j := choose(k.Group.Index(yT), len(k.Jumps))
+		xT.Add(xT, k.Jumps[j])
+		yT = k.Group.Op(yT, gs[j])
+		stats.Tame++
+	}
+
+	xW := &big.Int{}
+	yW := y
+	max := (&big.Int{}).Add(w, xT)
+	for xW.Cmp(max) <= 0 {
+		j := choose(k.Group.Index(yW), len(k.Jumps))
+		xW.Add(xW, k.Jumps[j])
+		yW = k.Group.Op(yW, gs[j])
+		stats.Wild++
+		if k.Group.Equal(yW, yT) {
+			x := (&big.Int{}).Add(b, xT)
+			return x.Sub(x, xW), stats, nil
+		}
+	}
+	return nil, stats, ErrNotFound
+}
@@ -0,0 +1,43 @@
+package dlog
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestKangaroo(t *testing.T) {
+	// 1000003 is prime, 5 generates a large subgroup
+	p := big.NewInt(1000003)
+	g := big.NewInt(5)
+	group := ModP{p}
+	a, b := big.NewInt(1000), big.NewInt(1<<16)
+	for _, x := range []int64{1000, 4242, 31337, 1 << 16} {
+		y := group.Exp(g, big.NewInt(x))
+		k := NewKangaroo(group, a, b)
+		found, stats, err := k.Search(g, y, a, b)
+		if err != nil {
+			t.Fatalf("%d: %v (%s)", x, err, stats)
+		}
+		if found.Int64() != x {
+			t.Fatalf("found %s, expected %d", found, x)
+		}
+	}
+}
+
+func TestCRT(t *testing.T) {
+	as := []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(1)}
+	ms := []*big.Int{big.NewInt(3), big.NewInt(4), big.NewInt(5)}
+	x, m := CRT(as, ms)
+	if x.Int64() != 11 || m.Int64() != 60 {
+		t.Fatalf("CRT = %s mod %s, expected 11 mod 60", x, m)
+	}
+}
+
+func TestSmallFactors(t *testing.T) {
+	// 2^3 * 3 * 5^2 * 1000003
+	n := big.NewInt(8 * 3 * 25 * 1000003)
+	fs := SmallFactors(n, 1<<16)
+	if len(fs) != 3 || fs[0].Int64() != 2 || fs[1].Int64() != 3 || fs[2].Int64() != 5 {
+		t.Fatalf("unexpected factors %v", fs)
+	}
+}
@@ -0,0 +1,59 @@
+package dlog
+
+import "math/big"
+
+// Factor is the prime power P^E.
+type Factor struct {
+	P *big.Int
+	E int
+}
+
+func (f Factor) power() *big.Int {
+	return (&big.Int{}).Exp(f.P, big.NewInt(int64(f.E)), nil)
+}
+
+// Brute finds x in [0, n) such that g^x = y by exhaustive search.
+func Brute(group Group, g, y Element, n *big.Int) (*big.Int, error) {
+	cur := group.Exp(g, big.NewInt(0))
+	for x := big.NewInt(0); x.Cmp(n) < 0; x.Add(x, one) {
+		if group.Equal(cur, y) {
+			return x, nil
+		}
+		cur = group.Op(cur, g)
+	}
+	return nil, ErrNotFound
+}
+
+// PohligHellman finds x such that g^x = y, where g has the given order
+// and order is the product of factors. The logarithm is brute forced in
+// each subgroup of prime order, so the factors must be small.
+func PohligHellman(group Group, g, y Element, order *big.Int, factors []Factor) (*big.Int, error) {
+	var as, ms []*big.Int
+	for _, f := range factors {
+		qe := f.power()
+		cofactor := (&big.Int{}).Div(order, qe)
+		gi := group.Exp(g, cofactor)
+		yi := group.Exp(y, cofactor)
+		// gamma has order q
+		gamma := group.Exp(gi, (&big.Int{}).Div(qe, f.P))
+		x := big.NewInt(0)
+		qk := big.NewInt(1)
+		for k := 0; k < f.E; k++ {
+			// h = (gi^-x * yi)^(q^(e-1-k))
+			inv := (&big.Int{}).Sub(qe, x)
+			h := group.Op(group.Exp(gi, inv), yi)
+			e := (&big.Int{}).Exp(f.P, big.NewInt(int64(f.E-1-k)), nil)
+			h = group.Exp(h, e)
+			d, err := Brute(group, gamma, h, f.P)
+			if err != nil {
+				return nil, err
+			}
+			x.Add(x, d.Mul(d, qk))
+			qk.Mul(qk, f.P)
+		}
+		as = append(as, x)
+		ms = append(ms, qe)
+	}
+	x, _ := CRT(as, ms)
+	return x, nil
+}
@@ -0,0 +1,25 @@
+package dlog
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPohligHellman(t *testing.T) {
+	// 8100 = 2^2 * 3^4 * 5^2, 6 is a primitive root modulo 8101
+	p := big.NewInt(8101)
+	g := big.NewInt(6)
+	group := ModP{p}
+	order := big.NewInt(8100)
+	factors := []Factor{{big.NewInt(2), 2}, {big.NewInt(3), 4}, {big.NewInt(5), 2}}
+	for _, x := range []int64{0, 1, 7, 4242, 8099} {
+		y := group.Exp(g, big.NewInt(x))
+		found, err := PohligHellman(group, g, y, order, factors)
+		if err != nil {
+			t.Fatalf("%d: %v", x, err)
+		}
+		if found.Int64() != x {
+			t.Fatalf("found %s, expected %d", found, x)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// 54-nostradamus -k 8 -diamond diamond.gob predict
+// 54-nostradamus -diamond diamond.gob forge "Predictions: ..."
+
+const (
+	blockSize = 16
+	// Size of the weakened hash state in bytes
+	hashSize = 3
+	hashMask = 1<<(8*hashSize) - 1
+)
+
+type state uint32
+
+var iv = state(0x4a4b4c & hashMask)
+
+func (s state) key() []byte {
+	key := make([]byte, blockSize)
+	binary.BigEndian.PutUint32(key, uint32(s))
+	return key
+}
+
+// weakened Merkle-Damgard compression: AES keyed with the state,
+// truncated to hashSize bytes.
+func compress(h state, block []byte) state {
+	cph, err := aes.NewCipher(h.key())
+	if err != nil {
+		log.Fatalf("cannot create AES cipher: %v", err)
+	}
+	out := make([]byte, blockSize)
+	cph.Encrypt(out, block)
+	return state(binary.BigEndian.Uint32(out) >> (32 - 8*hashSize))
+}
+
+func hashBlocks(h state, msg []byte) state {
+	for i := 0; i+blockSize <= len(msg); i += blockSize {
+		h = compress(h, msg[i:i+blockSize])
+	}
+	return h
+}
+
+func mdglue(ml int) []byte {
+	tmplen := 0
+	if ml%blockSize < blockSize-8 {
+		tmplen = blockSize - 8 - ml%blockSize
+	} else {
+		tmplen = 2*blockSize - 8 - ml%blockSize
+	}
+	tmp := make([]byte, tmplen+8)
+	tmp[0] = 0x80
+	binary.BigEndian.PutUint64(tmp[tmplen:], uint64(ml*8))
+	return tmp
+}
+
+func mdhash(msg []byte) state {
+	return hashBlocks(iv, append(msg, mdglue(len(msg))...))
+}
+
+func (s state) bytes() []byte {
+	return s.key()[4-hashSize : 4]
+}
+
+// collide finds two blocks taking a and b to the same state.
+func collide(a, b state, rnd *rand.Rand) (ba, bb []byte, h state) {
+	ma := make(map[state][]byte)
+	mb := make(map[state][]byte)
+	for {
+		block := make([]byte, blockSize)
+		rnd.Read(block)
+		ha := compress(a, block)
+		if m, ok := mb[ha]; ok {
+			return block, m, ha
+		}
+		ma[ha] = block
+		hb := compress(b, block)
+		if m, ok := ma[hb]; ok {
+			return m, block, hb
+		}
+		mb[hb] = block
+	}
+}
+
+// States[i][j] goes to States[i+1][j/2] when compressed with Blocks[i][j].
+type diamond struct {
+	K            int
+	PrefixBlocks int
+	States       [][]state
+	Blocks       [][][]byte
+}
+
+func newDiamond(k, prefixBlocks int, rnd *rand.Rand) *diamond {
+	d := &diamond{
+		K:            k,
+		PrefixBlocks: prefixBlocks,
+		States:       make([][]state, k+1),
+		Blocks:       make([][][]byte, k),
+	}
+	leaves := make([]state, 0, 1<<uint(k))
+	seen := make(map[state]bool)
+	for len(leaves) < 1<<uint(k) {
+		s := state(rnd.Uint32() & hashMask)
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		leaves = append(leaves, s)
+	}
+	d.States[0] = leaves
+	for i := 0; i < k; i++ {
+		level := d.States[i]
+		d.States[i+1] = make([]state, len(level)/2)
+		d.Blocks[i] = make([][]byte, len(level))
+		for j := 0; j < len(level); j += 2 {
+			ba, bb, h := collide(level[j], level[j+1], rnd)
+			d.Blocks[i][j] = ba
+			d.Blocks[i][j+1] = bb
+			d.States[i+1][j/2] = h
+		}
+	}
+	return d
+}
+
+func loadDiamond(fname string) (*diamond, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	d := &diamond{}
+	if err := gob.NewDecoder(f).Decode(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *diamond) save(fname string) error {
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(d); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (d *diamond) root() state {
+	return d.States[d.K][0]
+}
+
+// length of every forged message: prefix, linking block and the path to the root
+func (d *diamond) msglen() int {
+	return (d.PrefixBlocks + 1 + d.K) * blockSize
+}
+
+func (d *diamond) prediction() state {
+	return hashBlocks(d.root(), mdglue(d.msglen()))
+}
+
+// pad fills the prefix with spaces up to the committed number of blocks
+func (d *diamond) pad(prefix []byte) ([]byte, error) {
+	size := d.PrefixBlocks * blockSize
+	if len(prefix) > size {
+		return nil, fmt.Errorf("prefix is %d bytes, at most %d allowed", len(prefix), size)
+	}
+	return append(prefix, bytes.Repeat([]byte(" "), size-len(prefix))...), nil
+}
+
+func (d *diamond) forge(prefix []byte, rnd *rand.Rand) ([]byte, error) {
+	msg, err := d.pad(prefix)
+	if err != nil {
+		return nil, err
+	}
+	h := hashBlocks(iv, msg)
+	leaves := make(map[state]int)
+	for j, s := range d.States[0] {
+		leaves[s] = j
+	}
+	link := make([]byte, blockSize)
+	var j int
+	for {
+		rnd.Read(link)
+		if n, ok := leaves[compress(h, link)]; ok {
+			j = n
+			break
+		}
+	}
+	msg = append(msg, link...)
+	for i := 0; i < d.K; i++ {
+		msg = append(msg, d.Blocks[i][j]...)
+		j /= 2
+	}
+	return msg, nil
+}
+
+func main() {
+	k := flag.Int("k", 8, "diamond structure has 2^k leaves")
+	blocks := flag.Int("blocks", 8, "number of blocks reserved for the predictions")
+	fname := flag.String("diamond", "diamond.gob", "file where the diamond structure is cached")
+	flag.Parse()
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	d, err := loadDiamond(*fname)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Fatalf("cannot load diamond structure: %v", err)
+		}
+		d = newDiamond(*k, *blocks, rnd)
+		if err := d.save(*fname); err != nil {
+			log.Fatalf("cannot save diamond structure: %v", err)
+		}
+	}
+
+	switch flag.Arg(0) {
+	case "predict":
+		fmt.Printf("%s\n", hex.EncodeToString(d.prediction().bytes()))
+	case "forge":
+		msg, err := d.forge([]byte(flag.Arg(1)), rnd)
+		if err != nil {
+			log.Fatalf("cannot forge message: %v", err)
+		}
+		fmt.Printf("%s %q\n", hex.EncodeToString(mdhash(msg).bytes()), msg)
+	default:
+		prediction := d.prediction()
+		msg, err := d.forge([]byte("Predictions: Red Sox 4, Yankees 2"), rnd)
+		if err != nil {
+			log.Fatalf("cannot forge message: %v", err)
+		}
+		if h := mdhash(msg); h != prediction {
+			log.Fatalf("forged hash %x, predicted %x", h, prediction)
+		}
+		fmt.Printf("Predicted %s: %q\n", hex.EncodeToString(prediction.bytes()), msg)
+	}
+}
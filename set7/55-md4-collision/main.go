@@ -0,0 +1,426 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/bits"
+	"math/rand"
+	"time"
+)
+
+// The size of an MD4 checksum in bytes.
+const Size = 16
+
+// The blocksize of MD4 in bytes.
+const BlockSize = 64
+
+const (
+	_Chunk = 64
+	_Init0 = 0x67452301
+	_Init1 = 0xEFCDAB89
+	_Init2 = 0x98BADCFE
+	_Init3 = 0x10325476
+)
+
+var shift1 = []uint{3, 7, 11, 19}
+var shift2 = []uint{3, 5, 9, 13}
+var shift3 = []uint{3, 9, 11, 15}
+
+var xIndex2 = []uint{0, 4, 8, 12, 1, 5, 9, 13, 2, 6, 10, 14, 3, 7, 11, 15}
+var xIndex3 = []uint{0, 8, 4, 12, 2, 10, 6, 14, 1, 9, 5, 13, 3, 11, 7, 15}
+
+type md4 struct {
+	s   [4]uint32
+	x   [_Chunk]byte
+	nx  int
+	len uint64
+}
+
+func newMD4() *md4 {
+	d := &md4{}
+	d.s[0] = _Init0
+	d.s[1] = _Init1
+	d.s[2] = _Init2
+	d.s[3] = _Init3
+	return d
+}
+
+func md4block(dig *md4, p []byte) int {
+	a := dig.s[0]
+	b := dig.s[1]
+	c := dig.s[2]
+	d := dig.s[3]
+	n := 0
+	var X [16]uint32
+	for len(p) >= _Chunk {
+		aa, bb, cc, dd := a, b, c, d
+
+		j := 0
+		for i := 0; i < 16; i++ {
+			X[i] = uint32(p[j]) | uint32(p[j+1])<<8 | uint32(p[j+2])<<16 | uint32(p[j+3])<<24
+			j += 4
+		}
+
+		// Round 1.
+		for i := uint(0); i < 16; i++ {
+			x := i
+			s := shift1[i%4]
+			f := ((c ^ d) & b) ^ d
+			a += f + X[x]
+			a = a<<s | a>>(32-s)
+			a, b, c, d = d, a, b, c
+		}
+
+		// Round 2.
+		for i := uint(0); i < 16; i++ {
+			x := xIndex2[i]
+			s := shift2[i%4]
+			g := (b & c) | (b & d) | (c & d)
+			a += g + X[x] + 0x5a827999
+			a = a<<s | a>>(32-s)
+			a, b, c, d = d, a, b, c
+		}
+
+		// Round 3.
+		for i := uint(0); i < 16; i++ {
+			x := xIndex3[i]
+			s := shift3[i%4]
+			h := b ^ c ^ d
+			a += h + X[x] + 0x6ed9eba1
+			a = a<<s | a>>(32-s)
+			a, b, c, d = d, a, b, c
+		}
+
+		a += aa
+		b += bb
+		c += cc
+		d += dd
+
+		p = p[_Chunk:]
+		n += _Chunk
+	}
+
+	dig.s[0] = a
+	dig.s[1] = b
+	dig.s[2] = c
+	dig.s[3] = d
+	return n
+}
+
+func md4glue(ml int) []byte {
+	tmplen := 0
+	if ml%64 < 56 {
+		tmplen = 56 - ml%64
+	} else {
+		tmplen = 64 + 56 - ml%64
+	}
+	tmp := make([]byte, tmplen+8)
+	tmp[0] = 0x80
+	binary.LittleEndian.PutUint64(tmp[tmplen:], uint64(ml*8))
+	return tmp
+}
+
+func md4sum(in []byte) []byte {
+	in = append(in[:len(in):len(in)], md4glue(len(in))...)
+	d := newMD4()
+	md4block(d, in)
+	out := make([]byte, Size)
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint32(out[i*4:], d.s[i])
+	}
+	return out
+}
+
+// Indexes of the intermediate states: q[i+4] is the output of step i,
+// q[0:4] are the initial a0, d0, c0, b0.
+const (
+	a0 = iota
+	d0
+	c0
+	b0
+	a1
+	d1
+	c1
+	b1
+	a2
+	d2
+	c2
+	b2
+	a3
+	d3
+	c3
+	b3
+	a4
+	d4
+	c4
+	b4
+	a5
+	d5
+	c5
+	b5
+	a6
+	d6
+	c6
+	b9  = 39
+	a10 = 40
+)
+
+type states [52]uint32
+
+func ff(x, y, z uint32) uint32 { return ((y ^ z) & x) ^ z }
+func gg(x, y, z uint32) uint32 { return (x & y) | (x & z) | (y & z) }
+func hh(x, y, z uint32) uint32 { return x ^ y ^ z }
+
+// step computes q[i+4] from the message word for step i
+func (q *states) step(i int, m uint32) uint32 {
+	switch {
+	case i < 16:
+		return bits.RotateLeft32(q[i]+ff(q[i+3], q[i+2], q[i+1])+m, int(shift1[i%4]))
+	case i < 32:
+		return bits.RotateLeft32(q[i]+gg(q[i+3], q[i+2], q[i+1])+m+0x5a827999, int(shift2[i%4]))
+	}
+	return bits.RotateLeft32(q[i]+hh(q[i+3], q[i+2], q[i+1])+m+0x6ed9eba1, int(shift3[i%4]))
+}
+
+// word returns the round 1 message word that makes step i output v
+func (q *states) word(i int, v uint32) uint32 {
+	return bits.RotateLeft32(v, -int(shift1[i%4])) - q[i] - ff(q[i+3], q[i+2], q[i+1])
+}
+
+// index returns the message word used by step i
+func index(i int) uint {
+	switch {
+	case i < 16:
+		return uint(i)
+	case i < 32:
+		return xIndex2[i-16]
+	}
+	return xIndex3[i-32]
+}
+
+// compute computes the states of the first n steps
+func (q *states) compute(m *[16]uint32, n int) {
+	q[a0], q[d0], q[c0], q[b0] = _Init0, _Init3, _Init2, _Init1
+	for i := 0; i < n; i++ {
+		q[i+4] = q.step(i, m[index(i)])
+	}
+}
+
+// cond is a sufficient condition on bit (1-based, as in the paper) of
+// state q: the bit must equal val, or q[ref]'s bit xor val if ref is set.
+type cond struct {
+	q, bit int
+	ref    int
+	val    uint32
+}
+
+func zero(q, bit int) cond     { return cond{q, bit, -1, 0} }
+func one(q, bit int) cond      { return cond{q, bit, -1, 1} }
+func eq(q, bit, ref int) cond  { return cond{q, bit, ref, 0} }
+func neq(q, bit, ref int) cond { return cond{q, bit, ref, 1} }
+
+func (c cond) want(q *states) uint32 {
+	if c.ref < 0 {
+		return c.val
+	}
+	return (q[c.ref]>>uint(c.bit-1))&1 ^ c.val
+}
+
+func (c cond) ok(q *states) bool {
+	return (q[c.q]>>uint(c.bit-1))&1 == c.want(q)
+}
+
+func (c cond) fix(q *states, v uint32) uint32 {
+	b := uint32(1) << uint(c.bit-1)
+	return v&^b | c.want(q)<<uint(c.bit-1)
+}
+
+// Sufficient conditions from Wang et al., "Cryptanalysis of the Hash
+// Functions MD4 and RIPEMD", table 6.
+var conds = []cond{
+	eq(a1, 7, b0),
+	zero(d1, 7), eq(d1, 8, a1), eq(d1, 11, a1),
+	one(c1, 7), one(c1, 8), zero(c1, 11), eq(c1, 26, d1),
+	one(b1, 7), zero(b1, 8), zero(b1, 11), zero(b1, 26),
+	one(a2, 8), one(a2, 11), zero(a2, 26), eq(a2, 14, b1),
+	zero(d2, 14), eq(d2, 19, a2), eq(d2, 20, a2), eq(d2, 21, a2), eq(d2, 22, a2), one(d2, 26),
+	eq(c2, 13, d2), zero(c2, 14), eq(c2, 15, d2), zero(c2, 19), zero(c2, 20), one(c2, 21), zero(c2, 22),
+	one(b2, 13), one(b2, 14), zero(b2, 15), eq(b2, 17, c2), zero(b2, 19), zero(b2, 20), zero(b2, 21), zero(b2, 22),
+	one(a3, 13), one(a3, 14), one(a3, 15), zero(a3, 17), zero(a3, 19), zero(a3, 20), zero(a3, 21), one(a3, 22), eq(a3, 23, b2), eq(a3, 26, b2),
+	one(d3, 13), one(d3, 14), one(d3, 15), zero(d3, 17), zero(d3, 20), one(d3, 21), one(d3, 22), zero(d3, 23), one(d3, 26), eq(d3, 30, a3),
+	one(c3, 17), zero(c3, 20), zero(c3, 21), zero(c3, 22), zero(c3, 23), zero(c3, 26), one(c3, 30), eq(c3, 32, d3),
+	zero(b3, 20), one(b3, 21), one(b3, 22), eq(b3, 23, c3), one(b3, 26), zero(b3, 30), zero(b3, 32),
+	zero(a4, 23), zero(a4, 26), eq(a4, 27, b3), eq(a4, 29, b3), one(a4, 30), zero(a4, 32),
+	zero(d4, 23), zero(d4, 26), one(d4, 27), one(d4, 29), zero(d4, 30), one(d4, 32),
+	eq(c4, 19, d4), one(c4, 23), one(c4, 26), zero(c4, 27), zero(c4, 29), zero(c4, 30),
+	zero(b4, 19), one(b4, 26), one(b4, 27), one(b4, 29), zero(b4, 30),
+	eq(a5, 19, c4), one(a5, 26), zero(a5, 27), one(a5, 29), one(a5, 32),
+	eq(d5, 19, a5), eq(d5, 26, b4), eq(d5, 27, b4), eq(d5, 29, b4), eq(d5, 32, b4),
+	eq(c5, 26, d5), eq(c5, 27, d5), eq(c5, 29, d5), eq(c5, 30, d5), eq(c5, 32, d5),
+	eq(b5, 29, c5), one(b5, 30), zero(b5, 32),
+	one(a6, 29), one(a6, 32),
+	eq(d6, 29, b5),
+	eq(c6, 29, d6), neq(c6, 30, d6), neq(c6, 32, d6),
+	one(b9, 32),
+	one(a10, 32),
+}
+
+// satisfied checks the first n conditions
+func satisfied(q *states, n int) bool {
+	for _, c := range conds[:n] {
+		if !c.ok(q) {
+			return false
+		}
+	}
+	return true
+}
+
+// check computes the states of m step by step and stops at the first
+// condition that fails, most tries are rejected early in round 2.
+func (q *states) check(m *[16]uint32) bool {
+	q[a0], q[d0], q[c0], q[b0] = _Init0, _Init3, _Init2, _Init1
+	n := 0
+	for i := 0; i < 48; i++ {
+		q[i+4] = q.step(i, m[index(i)])
+		for ; n < len(conds) && conds[n].q == i+4; n++ {
+			if !conds[n].ok(q) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// round1 applies the single-message modification: every round 1 state is
+// forced to satisfy its conditions and the message word is derived from it.
+func round1(m *[16]uint32) {
+	var q states
+	q[a0], q[d0], q[c0], q[b0] = _Init0, _Init3, _Init2, _Init1
+	for i := 0; i < 16; i++ {
+		v := q.step(i, m[i])
+		for _, c := range conds {
+			if c.q == i+4 {
+				v = c.fix(&q, v)
+			}
+		}
+		m[i] = q.word(i, v)
+		q[i+4] = v
+	}
+}
+
+// tweak adds delta to m[k] and recomputes the next four message words so
+// that all round 1 states but the one of step k are left untouched.
+func tweak(m *[16]uint32, k int, delta uint32) {
+	var q states
+	q.compute(m, 16)
+	old := q
+	m[k] += delta
+	q[k+4] = q.step(k, m[k])
+	for j := k + 1; j <= k+4 && j < 16; j++ {
+		m[j] = q.word(j, old[j+4])
+	}
+}
+
+// Round 2 states with the message word k that corrects them and the shift
+// of their step. Changing a word also moves the round 1 state it produces
+// and the words recomputed after it, which can break earlier round 2
+// conditions: the later a state, the less often its correction is kept.
+var round2 = []struct {
+	q, k, s int
+}{
+	{a5, 0, 3},
+	{d5, 4, 5},
+	{c5, 8, 9},
+	{b5, 12, 13},
+	{a6, 1, 3},
+	{d6, 5, 5},
+	{c6, 9, 9},
+}
+
+// round2fix applies the multi-message modifications: a bit of the round 2 state
+// is flipped through its message word, the change is absorbed in round 1 and
+// reverted if it breaks any condition already satisfied.
+func round2fix(m *[16]uint32) {
+	var q states
+	for _, r := range round2 {
+		for i, c := range conds {
+			if c.q != r.q {
+				continue
+			}
+			q.compute(m, r.q-3)
+			if c.ok(&q) {
+				continue
+			}
+			delta := uint32(1) << uint((c.bit-1-r.s+32)%32)
+			if (q[r.q]>>uint(c.bit-1))&1 != 0 {
+				delta = -delta
+			}
+			saved := *m
+			tweak(m, r.k, delta)
+			q.compute(m, r.q-3)
+			if !satisfied(&q, i+1) {
+				*m = saved
+			}
+		}
+	}
+}
+
+func wordBytes(m *[16]uint32) []byte {
+	bs := make([]byte, BlockSize)
+	for i, w := range m {
+		binary.LittleEndian.PutUint32(bs[i*4:], w)
+	}
+	return bs
+}
+
+// differential of the colliding message
+func pair(m *[16]uint32) [16]uint32 {
+	m1 := *m
+	m1[1] += 1 << 31
+	m1[2] += 1<<31 - 1<<28
+	m1[12] -= 1 << 16
+	return m1
+}
+
+func collide(rnd *rand.Rand) ([]byte, []byte, int) {
+	var (
+		m     [16]uint32
+		q     states
+		tries int
+	)
+	for {
+		tries++
+		for i := range m {
+			m[i] = rnd.Uint32()
+		}
+		round1(&m)
+		round2fix(&m)
+		if !q.check(&m) {
+			continue
+		}
+		m1 := pair(&m)
+		x0, x1 := wordBytes(&m), wordBytes(&m1)
+		s0, s1 := newMD4(), newMD4()
+		md4block(s0, x0)
+		md4block(s1, x1)
+		if s0.s == s1.s {
+			return x0, x1, tries
+		}
+	}
+}
+
+func main() {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	t := time.Now()
+	m0, m1, tries := collide(rnd)
+	h0, h1 := md4sum(m0), md4sum(m1)
+	if bytes.Equal(m0, m1) || !bytes.Equal(h0, h1) {
+		log.Fatalf("not a collision: %x != %x", h0, h1)
+	}
+	fmt.Printf("Found after %d tries in %s\n", tries, time.Since(t))
+	fmt.Printf("%s\n%s\n", hex.EncodeToString(m0), hex.EncodeToString(m1))
+	fmt.Printf("md4: %s\n", hex.EncodeToString(h0))
+}
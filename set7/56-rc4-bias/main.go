@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dullgiulio/cryptopals-challenge/set3/randtest"
+)
+
+// 56-rc4-bias -n 24 -workers 8
+// 56-rc4-bias -stats
+
+const secretCookie = "QkUgU1VSRSBUTyBEUklOSyBZT1VSIE9WQUxUSU5F"
+
+type rc4 struct {
+	s    [256]byte
+	i, j uint8
+}
+
+func newRC4(key []byte) *rc4 {
+	c := &rc4{}
+	c.reset(key)
+	return c
+}
+
+func (c *rc4) reset(key []byte) {
+	for i := 0; i < 256; i++ {
+		c.s[i] = byte(i)
+	}
+	var j uint8
+	for i := 0; i < 256; i++ {
+		j += c.s[i] + key[i%len(key)]
+		c.s[i], c.s[j] = c.s[j], c.s[i]
+	}
+	c.i, c.j = 0, 0
+}
+
+func (c *rc4) XORKeyStream(dst, src []byte) {
+	i, j := c.i, c.j
+	for k := range src {
+		i++
+		j += c.s[i]
+		c.s[i], c.s[j] = c.s[j], c.s[i]
+		dst[k] = src[k] ^ c.s[c.s[i]+c.s[j]]
+	}
+	c.i, c.j = i, j
+}
+
+type oracle struct {
+	cookie []byte
+}
+
+func newOracle() *oracle {
+	cookie, err := base64.StdEncoding.DecodeString(secretCookie)
+	if err != nil {
+		log.Fatalf("cannot decode secret cookie: %v", err)
+	}
+	return &oracle{cookie}
+}
+
+// encrypt writes RC4(req || cookie) into dst under a fresh key read from keys
+func (o *oracle) encrypt(dst, req []byte, keys io.Reader, c *rc4) []byte {
+	var key [16]byte
+	if _, err := io.ReadFull(keys, key[:]); err != nil {
+		log.Fatalf("cannot generate random key: %v", err)
+	}
+	c.reset(key[:])
+	dst = append(append(dst[:0], req...), o.cookie...)
+	c.XORKeyStream(dst, dst)
+	return dst
+}
+
+// Single-byte biases of the RC4 keystream: Z16 towards 240, Z32 towards 224.
+var biases = []struct {
+	pos  int
+	want byte
+}{
+	{15, 240},
+	{31, 224},
+}
+
+type counts [2][256]uint64
+
+// share is the number of encryptions of worker w, the first n%workers
+// workers do one more
+func share(n, workers, w int) int {
+	if w < n%workers {
+		return n/workers + 1
+	}
+	return n / workers
+}
+
+// count encrypts the cookie n times behind a prefix of length pad, counting
+// the ciphertext bytes found at the biased positions.
+func (o *oracle) count(pad, n, workers int, done *uint64) *counts {
+	var (
+		wg  sync.WaitGroup
+		mux sync.Mutex
+	)
+	total := &counts{}
+	req := make([]byte, pad)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			var (
+				cnt counts
+				c   rc4
+				buf []byte
+			)
+			keys := bufio.NewReaderSize(rand.Reader, 1<<16)
+			for i := 0; i < n; i++ {
+				buf = o.encrypt(buf, req, keys, &c)
+				for b, bias := range biases {
+					if bias.pos < len(buf) {
+						cnt[b][buf[bias.pos]]++
+					}
+				}
+				if i&0xffff == 0xffff {
+					atomic.AddUint64(done, 0x10000)
+				}
+			}
+			mux.Lock()
+			for b := range cnt {
+				for v := range cnt[b] {
+					total[b][v] += cnt[b][v]
+				}
+			}
+			mux.Unlock()
+		}(share(n, workers, w))
+	}
+	wg.Wait()
+	return total
+}
+
+func progress(done *uint64, total uint64, stop chan struct{}) {
+	t := time.NewTicker(10 * time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			d := atomic.LoadUint64(done)
+			log.Printf("%d/%d encryptions (%.1f%%)", d, total, float64(d)*100/float64(total))
+		case <-stop:
+			return
+		}
+	}
+}
+
+func recoverCookie(o *oracle, n, workers int) []byte {
+	size := len(o.encrypt(nil, nil, rand.Reader, &rc4{}))
+	plain := make([]byte, size)
+	var done uint64
+	stop := make(chan struct{})
+	go progress(&done, uint64(n*16), stop)
+	defer close(stop)
+	for pad := 15; pad >= 0; pad-- {
+		cnt := o.count(pad, n, workers, &done)
+		for b, bias := range biases {
+			i := bias.pos - pad
+			if i >= size {
+				continue
+			}
+			var (
+				max  uint64
+				best byte
+			)
+			for v, c := range cnt[b] {
+				if c > max {
+					max = c
+					best = byte(v) ^ bias.want
+				}
+			}
+			plain[i] = best
+		}
+		log.Printf("prefix %2d: %q", pad, plain)
+	}
+	return plain
+}
+
+func main() {
+	n := flag.Uint("n", 24, "log2 of encryptions per prefix length")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of parallel workers")
+	stats := flag.Bool("stats", false, "run the randomness tests on the keystream")
+	flag.Parse()
+	if *workers < 1 {
+		log.Fatalf("need at least one worker, got %d", *workers)
+	}
+	if *stats {
+		var key [16]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			log.Fatalf("cannot generate random key: %v", err)
+		}
+		ks := make([]byte, 1<<17)
+		newRC4(key[:]).XORKeyStream(ks, ks)
+		randtest.Report(os.Stdout, "RC4", ks)
+		return
+	}
+	o := newOracle()
+	fmt.Printf("%s\n", recoverCookie(o, 1<<*n, *workers))
+}
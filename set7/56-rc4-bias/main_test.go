@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	stdrc4 "crypto/rc4"
+	"testing"
+)
+
+func TestRC4(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	plain := []byte("Hello world, some crypto test that is a bit longer than a block")
+	expected := make([]byte, len(plain))
+	c, err := stdrc4.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.XORKeyStream(expected, plain)
+	ctxt := make([]byte, len(plain))
+	newRC4(key).XORKeyStream(ctxt, plain)
+	if bytes.Compare(ctxt, expected) != 0 {
+		t.Fatalf("%x != %x\n", ctxt, expected)
+	}
+}
+
+func TestShare(t *testing.T) {
+	for _, n := range []int{0, 1, 7, 100, 1 << 16} {
+		for workers := 1; workers < 10; workers++ {
+			sum := 0
+			for w := 0; w < workers; w++ {
+				sum += share(n, workers, w)
+			}
+			if sum != n {
+				t.Fatalf("%d workers share %d of %d encryptions", workers, sum, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/dullgiulio/cryptopals-challenge/set5/dh"
+)
+
+const (
+	px = "7199773997391911030609999317773941274322764333428698921736339643928346453700085358802973900485592910475480089726140708102474957429903531369589969318716771"
+	gx = "4565356397095740655436854503483826832136106141639563487732438195343690437606117828318042418238184896212352329118608100083187535033402010599512641674644143"
+	qx = "236234353446506858198510045061214171961"
+)
+
+func number(s string) *big.Int {
+	n, ok := (&big.Int{}).SetString(s, 10)
+	if !ok {
+		log.Fatalf("cannot parse number %s", s)
+	}
+	return n
+}
+
+func main() {
+	p, q, g := number(px), number(qx), number(gx)
+	group := &dh.Group{P: p, G: g, Q: q}
+	b, err := dh.NewBob(group)
+	if err != nil {
+		log.Fatalf("cannot generate key: %v", err)
+	}
+	x, m, err := dh.SubgroupAttack(group, b.Handle)
+	if err != nil {
+		log.Fatalf("cannot recover the key: %v", err)
+	}
+	if m.Cmp(q) <= 0 {
+		log.Fatalf("not enough small factors: %s <= q", m)
+	}
+	fmt.Printf("x = %s\n", x)
+	if x.Cmp(b.Key.Private) != 0 {
+		log.Fatalf("wrong key, Bob's is %s", b.Key.Private)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/dullgiulio/cryptopals-challenge/set5/dh"
+	"github.com/dullgiulio/cryptopals-challenge/set5/dlog"
+)
+
+const (
+	px = "11470374874925275658116663507232161402086650258453896274534991676898999262641581519101074740642369848233294239851519212341844337347119899874391456329785623"
+	qx = "335062023296420808191071248367701059461"
+	gx = "622952335333961296978159266084741085889881358738459939978290179936063635566740258555167783009058567397963466103140082647486611657350811560630587013183357"
+
+	y1x = "7760073848032689505395005705677365876654629189298052775754597607446617558600394076764814236081991643094239886772481052254010323780165093955236429914607119"
+	y2x = "9388897478013399550694114614498790691034187453089355259602614074132918843899833277397448144245883225611726912025846772975325932794909655215329941809013733"
+)
+
+var one = big.NewInt(1)
+
+func number(s string) *big.Int {
+	n, ok := (&big.Int{}).SetString(s, 10)
+	if !ok {
+		log.Fatalf("cannot parse number %s", s)
+	}
+	return n
+}
+
+func kangaroo(g, y, p, a, b *big.Int) *big.Int {
+	group := dlog.ModP{P: p}
+	x, stats, err := dlog.NewKangaroo(group, a, b).Search(g, y, a, b)
+	if err != nil {
+		log.Fatalf("kangaroo in [%s, %s]: %v (%s)", a, b, err, stats)
+	}
+	fmt.Printf("%s\n", stats)
+	return x
+}
+
+// attack combines the subgroup attack with the kangaroo: if x = n mod r,
+// then x = n + m*r and g'^m = y' with g' = g^r, y' = y*g^-n.
+func attack(group *dh.Group, bo *dh.Bob) (*big.Int, error) {
+	p, q, g := group.P, group.Q, group.G
+	n, r, err := dh.SubgroupAttack(group, bo.Handle)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("x = %s mod %s\n", n, r)
+	gr := (&big.Int{}).Exp(g, r, p)
+	gn := (&big.Int{}).Exp(g, n, p)
+	y := (&big.Int{}).ModInverse(gn, p)
+	y.Mul(y, bo.Key.Public)
+	y.Mod(y, p)
+	b := (&big.Int{}).Sub(q, one)
+	b.Div(b, r)
+	m := kangaroo(gr, y, p, big.NewInt(0), b)
+	x := m.Mul(m, r)
+	return x.Add(x, n), nil
+}
+
+func main() {
+	p, q, g := number(px), number(qx), number(gx)
+
+	for _, c := range []struct {
+		y    string
+		bits uint
+	}{
+		{y1x, 20},
+		{y2x, 40},
+	} {
+		b := (&big.Int{}).Lsh(one, c.bits)
+		x := kangaroo(g, number(c.y), p, big.NewInt(0), b)
+		fmt.Printf("y = g^%s\n", x)
+	}
+
+	group := &dh.Group{P: p, G: g, Q: q}
+	bo, err := dh.NewBob(group)
+	if err != nil {
+		log.Fatalf("cannot generate key: %v", err)
+	}
+	x, err := attack(group, bo)
+	if err != nil {
+		log.Fatalf("cannot recover the key: %v", err)
+	}
+	fmt.Printf("x = %s\n", x)
+	if x.Cmp(bo.Key.Private) != 0 {
+		log.Fatalf("wrong key, Bob's is %s", bo.Key.Private)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/dullgiulio/cryptopals-challenge/set5/dlog"
+	"github.com/dullgiulio/cryptopals-challenge/set8/ec"
+)
+
+const (
+	px  = "233970423115425145524320034830162017933"
+	gxx = "182"
+	gyx = "85518893674295321206118380980485522083"
+	nx  = "29246302889428143187362802287225875743"
+)
+
+var (
+	one = big.NewInt(1)
+	a   = big.NewInt(-95051)
+	b   = big.NewInt(11279326)
+)
+
+// Curves with the same a, but different b, and their group orders.
+var invalid = []struct {
+	b     int64
+	order string
+}{
+	{210, "233970423115425145550826547352470124412"},
+	{504, "233970423115425145544350131142039591210"},
+	{727, "233970423115425145545378039958152057148"},
+}
+
+func number(s string) *big.Int {
+	n, ok := (&big.Int{}).SetString(s, 10)
+	if !ok {
+		log.Fatalf("cannot parse number %s", s)
+	}
+	return n
+}
+
+func randInt(max *big.Int) *big.Int {
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		log.Fatalf("cannot generate random number: %v", err)
+	}
+	return n
+}
+
+type ecdh struct {
+	c    *ec.Curve
+	g    ec.Point
+	n    *big.Int
+	exp  *big.Int
+	A    ec.Point
+	peer ec.Point
+	key  []byte
+}
+
+func newECDH(c *ec.Curve, g ec.Point, n *big.Int) *ecdh {
+	d := &ecdh{
+		c: c,
+		g: g,
+		n: n,
+	}
+	d.exp = randInt(n)
+	d.A = c.ScalarMult(g, d.exp)
+	return d
+}
+
+func (d *ecdh) pub() ecdhmsg {
+	return &ecdhPub{d.A}
+}
+
+func (d *ecdh) setPeer(m *ecdhPub) {
+	d.peer = m.P
+}
+
+func shared(p ec.Point) []byte {
+	h := sha256.New()
+	if !p.IsInfinity() {
+		h.Write(p.X.Bytes())
+		h.Write(p.Y.Bytes())
+	}
+	return h.Sum(nil)
+}
+
+func (d *ecdh) compute() {
+	d.key = shared(d.c.ScalarMult(d.peer, d.exp))
+}
+
+type ecdhmsg interface {
+	apply(*ecdh)
+}
+
+type ecdhPub struct {
+	P ec.Point
+}
+
+func (m *ecdhPub) apply(d *ecdh) {
+	d.setPeer(m)
+}
+
+type macMsg struct {
+	msg, mac []byte
+}
+
+func mac(key, msg []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// bob MACs a message with the shared key, without validating the peer's point
+type bob struct {
+	*ecdh
+	msg []byte
+}
+
+func newBob(c *ec.Curve, g ec.Point, n *big.Int) *bob {
+	return &bob{
+		ecdh: newECDH(c, g, n),
+		msg:  []byte("crazy flamboyant for the rap enjoyment"),
+	}
+}
+
+func (b *bob) respond() *macMsg {
+	b.compute()
+	return &macMsg{b.msg, mac(b.key, b.msg)}
+}
+
+// attack sends points of small order on curves sharing Bob's a and finds
+// his key modulo each order from the MAC of the shared point.
+func attack(bo *bob, p *big.Int) *big.Int {
+	var as, ms []*big.Int
+	seen := make(map[int64]bool)
+	prod := big.NewInt(1)
+	for _, inv := range invalid {
+		c := ec.NewCurve(p, a, big.NewInt(inv.b))
+		order := number(inv.order)
+		for _, r := range dlog.SmallFactors(order, 1<<16) {
+			if seen[r.Int64()] {
+				continue
+			}
+			h, err := c.PointOfOrder(order, r, nil)
+			if err == ec.ErrNoPoint {
+				continue
+			}
+			if err != nil {
+				log.Fatalf("cannot find point of order %s: %v", r, err)
+			}
+			seen[r.Int64()] = true
+			var m ecdhmsg = &ecdhPub{h}
+			m.apply(bo.ecdh)
+			resp := bo.respond()
+			k := &big.Int{}
+			K := ec.Infinity()
+			for ; k.Cmp(r) < 0; k.Add(k, one) {
+				if hmac.Equal(mac(shared(K), resp.msg), resp.mac) {
+					break
+				}
+				K = c.Add(K, h)
+			}
+			as = append(as, k)
+			ms = append(ms, r)
+			prod.Mul(prod, r)
+			if prod.Cmp(bo.n) > 0 {
+				x, _ := dlog.CRT(as, ms)
+				return x
+			}
+		}
+	}
+	log.Fatalf("not enough small subgroups, only %s", prod)
+	return nil
+}
+
+func main() {
+	p := number(px)
+	c := ec.NewCurve(p, a, b)
+	g := ec.NewPoint(number(gxx), number(gyx))
+	n := number(nx)
+
+	alice := newECDH(c, g, n)
+	bo := newBob(c, g, n)
+	m := alice.pub()
+	m.apply(bo.ecdh)
+	m = bo.pub()
+	m.apply(alice)
+	alice.compute()
+	resp := bo.respond()
+	if !hmac.Equal(mac(alice.key, resp.msg), resp.mac) {
+		log.Fatal("Alice and Bob do not agree on the key")
+	}
+
+	x := attack(bo, p)
+	fmt.Printf("x = %s\n", x)
+	if x.Cmp(bo.exp) != 0 {
+		log.Fatalf("wrong key, Bob's is %s", bo.exp)
+	}
+}
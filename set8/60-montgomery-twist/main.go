@@ -0,0 +1,297 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/dullgiulio/cryptopals-challenge/set5/dlog"
+	"github.com/dullgiulio/cryptopals-challenge/set8/ec"
+)
+
+const (
+	px = "233970423115425145524320034830162017933"
+	nx = "29246302889428143187362802287225875743"
+)
+
+var (
+	one = big.NewInt(1)
+	a   = big.NewInt(534)
+	b   = big.NewInt(1)
+	gu  = big.NewInt(4)
+)
+
+func number(s string) *big.Int {
+	n, ok := (&big.Int{}).SetString(s, 10)
+	if !ok {
+		log.Fatalf("cannot parse number %s", s)
+	}
+	return n
+}
+
+func randInt(max *big.Int) *big.Int {
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		log.Fatalf("cannot generate random number: %v", err)
+	}
+	return n
+}
+
+// ecdh exchanges only u coordinates
+type ecdh struct {
+	m    *ec.Montgomery
+	u    *big.Int
+	n    *big.Int
+	exp  *big.Int
+	A    *big.Int
+	peer *big.Int
+	key  []byte
+}
+
+func newECDH(m *ec.Montgomery, u, n *big.Int) *ecdh {
+	d := &ecdh{
+		m: m,
+		u: u,
+		n: n,
+	}
+	d.exp = randInt(n)
+	d.A = m.Ladder(u, d.exp)
+	return d
+}
+
+func (d *ecdh) pub() ecdhmsg {
+	return &ecdhPub{d.A}
+}
+
+func (d *ecdh) setPeer(m *ecdhPub) {
+	d.peer = m.U
+}
+
+func shared(u *big.Int) []byte {
+	h := sha256.Sum256(u.Bytes())
+	return h[:]
+}
+
+func (d *ecdh) compute() {
+	d.key = shared(d.m.Ladder(d.peer, d.exp))
+}
+
+type ecdhmsg interface {
+	apply(*ecdh)
+}
+
+type ecdhPub struct {
+	U *big.Int
+}
+
+func (m *ecdhPub) apply(d *ecdh) {
+	d.setPeer(m)
+}
+
+type macMsg struct {
+	msg, mac []byte
+}
+
+func mac(key, msg []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// bob MACs a message with the shared key, he doesn't check that u is on the curve
+type bob struct {
+	*ecdh
+	msg []byte
+}
+
+func newBob(m *ec.Montgomery, u, n *big.Int) *bob {
+	return &bob{
+		ecdh: newECDH(m, u, n),
+		msg:  []byte("crazy flamboyant for the rap enjoyment"),
+	}
+}
+
+func (b *bob) respond() *macMsg {
+	b.compute()
+	return &macMsg{b.msg, mac(b.key, b.msg)}
+}
+
+func (b *bob) query(u *big.Int) *macMsg {
+	var m ecdhmsg = &ecdhPub{u}
+	m.apply(b.ecdh)
+	return b.respond()
+}
+
+// twist holds the quadratic twist of the curve in both forms
+type twist struct {
+	m     *ec.Montgomery
+	c     *ec.Curve
+	order *big.Int
+}
+
+func newTwist(m *ec.Montgomery, order *big.Int) *twist {
+	tw := m.Twist()
+	return &twist{tw, tw.Weierstrass(), order}
+}
+
+func (t *twist) u(p ec.Point) *big.Int {
+	u, _ := t.m.FromWeierstrass(p)
+	return u
+}
+
+func (t *twist) pointOfOrder(r *big.Int) (ec.Point, error) {
+	return t.c.PointOfOrder(t.order, r, nil)
+}
+
+// matches checks if Bob's MAC was computed with the u coordinate of p
+func matches(resp *macMsg, u *big.Int) bool {
+	return hmac.Equal(mac(shared(u), resp.msg), resp.mac)
+}
+
+// residue finds k in [0, r/2] such that Bob's key is +k or -k modulo the
+// order r of h.
+func residue(bo *bob, tw *twist, h ec.Point, r *big.Int) *big.Int {
+	resp := bo.query(tw.u(h))
+	half := (&big.Int{}).Rsh(r, 1)
+	K := ec.Infinity()
+	for k := big.NewInt(0); k.Cmp(half) <= 0; k.Add(k, one) {
+		if matches(resp, tw.u(K)) {
+			return k
+		}
+		K = tw.c.Add(K, h)
+	}
+	log.Fatalf("no residue found for order %s", r)
+	return nil
+}
+
+// twistAttack finds x = +a or -a modulo M. The sign ambiguity of every new
+// residue is resolved querying the sum of all points used so far.
+func twistAttack(bo *bob, tw *twist) (*big.Int, *big.Int) {
+	var (
+		a = big.NewInt(0)
+		M = big.NewInt(1)
+		H = ec.Infinity()
+	)
+	for _, r := range dlog.SmallFactors(tw.order, 1<<22) {
+		if r.Cmp(big.NewInt(2)) == 0 {
+			continue
+		}
+		h, err := tw.pointOfOrder(r)
+		if err != nil {
+			continue
+		}
+		k := residue(bo, tw, h, r)
+		H = tw.c.Add(H, h)
+		if M.Cmp(one) == 0 {
+			a, M = k, r
+			continue
+		}
+		resp := bo.query(tw.u(H))
+		mr := (&big.Int{}).Mul(M, r)
+		for _, kk := range []*big.Int{k, (&big.Int{}).Sub(r, k)} {
+			c, _ := dlog.CRT([]*big.Int{a, kk}, []*big.Int{M, r})
+			if matches(resp, tw.u(tw.c.ScalarMult(H, c))) {
+				a = c
+				break
+			}
+		}
+		M = mr
+		fmt.Printf("x = ±%s mod %s\n", a, M)
+	}
+	return a, M
+}
+
+// curveGroup lets the kangaroo walk on the curve
+type curveGroup struct {
+	*ec.Curve
+}
+
+func (g curveGroup) Op(x, y dlog.Element) dlog.Element {
+	return g.Add(x.(ec.Point), y.(ec.Point))
+}
+
+func (g curveGroup) Exp(x dlog.Element, n *big.Int) dlog.Element {
+	return g.ScalarMult(x.(ec.Point), n)
+}
+
+func (g curveGroup) Equal(x, y dlog.Element) bool {
+	return x.(ec.Point).Equal(y.(ec.Point))
+}
+
+func (g curveGroup) Index(x dlog.Element) *big.Int {
+	p := x.(ec.Point)
+	if p.IsInfinity() {
+		return big.NewInt(0)
+	}
+	return p.X
+}
+
+// attack recovers Bob's key from the twist residues, then searches the
+// remaining x = ±a + m*M with the kangaroo on the curve. Both the sign of a
+// and the v coordinate of Bob's public key are unknown.
+func attack(bo *bob, m *ec.Montgomery, order *big.Int) *big.Int {
+	tw := newTwist(m, order)
+	a, M := twistAttack(bo, tw)
+
+	c := m.Weierstrass()
+	gv, _ := m.V(bo.u)
+	g := m.ToWeierstrass(bo.u, gv)
+	pv, ok := m.V(bo.A)
+	if !ok {
+		log.Fatal("Bob's public key is not on the curve")
+	}
+	pub := m.ToWeierstrass(bo.A, pv)
+	gM := c.ScalarMult(g, M)
+	max := (&big.Int{}).Div(bo.n, M)
+	max.Add(max, one)
+	k := dlog.NewKangaroo(curveGroup{c}, big.NewInt(0), max)
+	for _, sl := range []int64{1, -1} {
+		for _, sa := range []int64{1, -1} {
+			sign := big.NewInt(sa)
+			aG := c.ScalarMult(g, (&big.Int{}).Mul(a, sign))
+			y := c.Add(c.ScalarMult(pub, big.NewInt(sl)), c.Neg(aG))
+			mm, stats, err := k.Search(gM, y, big.NewInt(0), max)
+			fmt.Printf("%s\n", stats)
+			if err != nil {
+				continue
+			}
+			x := mm.Mul(mm, M)
+			x.Add(x, (&big.Int{}).Mul(a, sign))
+			x.Mod(x, bo.n)
+			if m.Ladder(bo.u, x).Cmp(bo.A) == 0 {
+				return x
+			}
+		}
+	}
+	log.Fatal("kangaroo didn't find Bob's key")
+	return nil
+}
+
+func main() {
+	p, n := number(px), number(nx)
+	m := ec.NewMontgomery(p, a, b)
+	// the curve has order 8n, the twist 2p + 2 - 8n
+	order := (&big.Int{}).Lsh(p, 1)
+	order.Add(order, big.NewInt(2))
+	order.Sub(order, (&big.Int{}).Lsh(n, 3))
+
+	alice := newECDH(m, gu, n)
+	bo := newBob(m, gu, n)
+	msg := alice.pub()
+	msg.apply(bo.ecdh)
+	msg = bo.pub()
+	msg.apply(alice)
+	alice.compute()
+	if resp := bo.respond(); !hmac.Equal(mac(alice.key, resp.msg), resp.mac) {
+		log.Fatal("Alice and Bob do not agree on the key")
+	}
+
+	x := attack(bo, m, order)
+	fmt.Printf("x = %s\n", x)
+	if x.Cmp(bo.exp) != 0 && (&big.Int{}).Sub(n, x).Cmp(bo.exp) != 0 {
+		log.Fatalf("wrong key, Bob's is %s", bo.exp)
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	mrand "math/rand"
+	"time"
+
+	"github.com/dullgiulio/cryptopals-challenge/set5/dlog"
+	"github.com/dullgiulio/cryptopals-challenge/set8/ec"
+)
+
+const (
+	px  = "233970423115425145524320034830162017933"
+	gxx = "182"
+	gyx = "85518893674295321206118380980485522083"
+	nx  = "29246302889428143187362802287225875743"
+
+	// DigestInfo for SHA-256
+	sha256Prefix = "3031300d060960864801650304020105000420"
+)
+
+var (
+	one = big.NewInt(1)
+	two = big.NewInt(2)
+)
+
+func number(s string) *big.Int {
+	n, ok := (&big.Int{}).SetString(s, 10)
+	if !ok {
+		log.Fatalf("cannot parse number %s", s)
+	}
+	return n
+}
+
+func hash(d []byte) []byte {
+	h := sha256.Sum256(d)
+	return h[:]
+}
+
+// ecdsaDSKS returns domain parameters and a public key that verify the
+// signature (r, s) of hash: G' = (u1 + u2*d')^-1 * R and Q' = d'*G'.
+func ecdsaDSKS(params ec.Params, q ec.Point, hash []byte, r, s *big.Int) (ec.Params, *ec.PrivateKey) {
+	c := params.Curve
+	w := (&big.Int{}).ModInverse(s, params.N)
+	u1 := (&big.Int{}).Mul(params.HashToInt(hash), w)
+	u1.Mod(u1, params.N)
+	u2 := (&big.Int{}).Mul(r, w)
+	u2.Mod(u2, params.N)
+	R := c.Add(c.ScalarMult(params.G, u1), c.ScalarMult(q, u2))
+	for {
+		key, err := ec.GenerateKey(params, nil)
+		if err != nil {
+			log.Fatalf("cannot generate key: %v", err)
+		}
+		t := (&big.Int{}).Mul(u2, key.D)
+		t.Add(t, u1)
+		t.Mod(t, params.N)
+		if t.Sign() == 0 {
+			continue
+		}
+		t.ModInverse(t, params.N)
+		forged := ec.Params{Curve: c, G: c.ScalarMult(R, t), N: params.N}
+		key.Params = forged
+		key.Q = c.ScalarMult(forged.G, key.D)
+		return forged, key
+	}
+}
+
+func ecdsaDemo() {
+	p := number(px)
+	params := ec.Params{
+		Curve: ec.NewCurve(p, big.NewInt(-95051), big.NewInt(11279326)),
+		G:     ec.NewPoint(number(gxx), number(gyx)),
+		N:     number(nx),
+	}
+	alice, err := ec.GenerateKey(params, nil)
+	if err != nil {
+		log.Fatalf("cannot generate key: %v", err)
+	}
+	h := hash([]byte("hi mom"))
+	r, s, err := ec.Sign(nil, alice, h)
+	if err != nil {
+		log.Fatalf("cannot sign: %v", err)
+	}
+	if !ec.Verify(params, alice.Q, h, r, s) {
+		log.Fatal("Alice's signature is not valid")
+	}
+	forged, eve := ecdsaDSKS(params, alice.Q, h, r, s)
+	if !ec.Verify(forged, eve.Q, h, r, s) {
+		log.Fatal("Eve's key does not verify the signature")
+	}
+	fmt.Printf("ECDSA: Eve's key %s verifies Alice's signature\n", eve.Q)
+}
+
+type rsaKey struct {
+	N, e, d *big.Int
+}
+
+func newRSA(bits int) *rsaKey {
+	e := big.NewInt(65537)
+	for {
+		p, err := rand.Prime(rand.Reader, bits/2)
+		if err != nil {
+			log.Fatalf("cannot generate prime: %v", err)
+		}
+		q, err := rand.Prime(rand.Reader, bits/2)
+		if err != nil {
+			log.Fatalf("cannot generate prime: %v", err)
+		}
+		p1 := (&big.Int{}).Sub(p, one)
+		q1 := (&big.Int{}).Sub(q, one)
+		phi := p1.Mul(p1, q1)
+		d := (&big.Int{}).ModInverse(e, phi)
+		if d == nil || p.Cmp(q) == 0 {
+			continue
+		}
+		return &rsaKey{(&big.Int{}).Mul(p, q), e, d}
+	}
+}
+
+// pad encodes the SHA-256 of msg as PKCS#1 v1.5 for a k bytes modulus
+func pad(msg []byte, k int) *big.Int {
+	prefix, _ := hex.DecodeString(sha256Prefix)
+	t := append(prefix, hash(msg)...)
+	em := make([]byte, k)
+	em[1] = 0x01
+	for i := 2; i < k-len(t)-1; i++ {
+		em[i] = 0xff
+	}
+	copy(em[k-len(t):], t)
+	return (&big.Int{}).SetBytes(em)
+}
+
+func (k *rsaKey) size() int {
+	return (k.N.BitLen() + 7) / 8
+}
+
+func (k *rsaKey) sign(msg []byte) *big.Int {
+	return (&big.Int{}).Exp(pad(msg, k.size()), k.d, k.N)
+}
+
+func (k *rsaKey) verify(msg []byte, s *big.Int) bool {
+	m := (&big.Int{}).Exp(s, k.e, k.N)
+	return bytes.Equal(m.Bytes(), pad(msg, k.size()).Bytes())
+}
+
+var smallPrimes []*big.Int
+
+func init() {
+	for i := int64(3); i < 1<<12; i += 2 {
+		n := big.NewInt(i)
+		if n.ProbablyPrime(0) {
+			smallPrimes = append(smallPrimes, n)
+		}
+	}
+}
+
+// smoothPrime returns a prime p of the given size where p-1 = 2 * product of
+// distinct small primes, none of which is in used.
+func smoothPrime(bits int, used map[int64]bool, rnd *mrand.Rand) (*big.Int, []dlog.Factor) {
+	for {
+		p1 := big.NewInt(2)
+		factors := []dlog.Factor{{P: two, E: 1}}
+		seen := make(map[int64]bool)
+		for p1.BitLen() < bits {
+			f := smallPrimes[rnd.Intn(len(smallPrimes))]
+			if used[f.Int64()] || seen[f.Int64()] {
+				continue
+			}
+			seen[f.Int64()] = true
+			p1.Mul(p1, f)
+			factors = append(factors, dlog.Factor{P: f, E: 1})
+		}
+		p := (&big.Int{}).Add(p1, one)
+		if p.BitLen() == bits && p.ProbablyPrime(20) {
+			return p, factors
+		}
+	}
+}
+
+// generates reports if g generates the whole group modulo p
+func generates(g, p *big.Int, factors []dlog.Factor) bool {
+	p1 := (&big.Int{}).Sub(p, one)
+	for _, f := range factors {
+		e := (&big.Int{}).Div(p1, f.P)
+		if (&big.Int{}).Exp(g, e, p).Cmp(one) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// smoothLog picks a smooth prime p where s generates Z_p^* and returns
+// log_s(m) mod p-1.
+func smoothLog(s, m *big.Int, bits int, used map[int64]bool, rnd *mrand.Rand) (*big.Int, *big.Int) {
+	for {
+		p, factors := smoothPrime(bits, used, rnd)
+		if !generates(s, p, factors) {
+			continue
+		}
+		p1 := (&big.Int{}).Sub(p, one)
+		mp := (&big.Int{}).Mod(m, p)
+		x, err := dlog.PohligHellman(dlog.ModP{P: p}, (&big.Int{}).Mod(s, p), mp, p1, factors)
+		if err != nil {
+			continue
+		}
+		// e' has to be invertible
+		if (&big.Int{}).GCD(nil, nil, x, p1).Cmp(one) != 0 {
+			continue
+		}
+		for _, f := range factors[1:] {
+			used[f.P.Int64()] = true
+		}
+		return p, x
+	}
+}
+
+// rsaDSKS builds a key (N', e', d') such that s^e' = pad(m) mod N'
+func rsaDSKS(key *rsaKey, msg []byte, s *big.Int) *rsaKey {
+	m := pad(msg, key.size())
+	bits := key.N.BitLen()
+	rnd := mrand.New(mrand.NewSource(time.Now().UnixNano()))
+	for {
+		used := make(map[int64]bool)
+		p, ep := smoothLog(s, m, bits/2, used, rnd)
+		q, eq := smoothLog(s, m, bits-bits/2, used, rnd)
+		N := (&big.Int{}).Mul(p, q)
+		if N.Cmp(key.N) <= 0 || N.BitLen() != bits {
+			continue
+		}
+		// p-1 and q-1 only share the factor 2, both logs are odd
+		p1 := (&big.Int{}).Sub(p, one)
+		q1 := (&big.Int{}).Sub(q, one)
+		q1.Rsh(q1, 1)
+		e, _ := dlog.CRT([]*big.Int{ep, (&big.Int{}).Mod(eq, q1)}, []*big.Int{p1, q1})
+		lambda := (&big.Int{}).Mul(p1, q1)
+		d := (&big.Int{}).ModInverse(e, lambda)
+		if d == nil {
+			continue
+		}
+		return &rsaKey{N, e, d}
+	}
+}
+
+func rsaDemo() {
+	msg := []byte("hi mom")
+	alice := newRSA(1024)
+	s := alice.sign(msg)
+	if !alice.verify(msg, s) {
+		log.Fatal("Alice's signature is not valid")
+	}
+	eve := rsaDSKS(alice, msg, s)
+	if !eve.verify(msg, s) {
+		log.Fatal("Eve's key does not verify the signature")
+	}
+	fmt.Printf("RSA: Eve's key e' = %s verifies Alice's signature\n", eve.e)
+	other := []byte("hi dad")
+	if !eve.verify(other, eve.sign(other)) {
+		log.Fatal("Eve cannot sign with her key")
+	}
+}
+
+func main() {
+	ecdsaDemo()
+	rsaDemo()
+}
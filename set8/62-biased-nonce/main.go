@@ -0,0 +1,188 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+
+	"github.com/dullgiulio/cryptopals-challenge/set8/ec"
+	"github.com/dullgiulio/cryptopals-challenge/set8/lattice"
+)
+
+// 62-biased-nonce -n 22 -bits 8 -output sigs.json
+// 62-biased-nonce -input sigs.json
+
+const (
+	px  = "233970423115425145524320034830162017933"
+	gxx = "182"
+	gyx = "85518893674295321206118380980485522083"
+	nx  = "29246302889428143187362802287225875743"
+)
+
+func number(s string) *big.Int {
+	n, ok := (&big.Int{}).SetString(s, 10)
+	if !ok {
+		log.Fatalf("cannot parse number %s", s)
+	}
+	return n
+}
+
+type signature struct {
+	Hash string `json:"hash"`
+	R    string `json:"r"`
+	S    string `json:"s"`
+}
+
+// dump is the format of the signatures file
+type dump struct {
+	X          string      `json:"x"`
+	Y          string      `json:"y"`
+	Bits       uint        `json:"bits"`
+	Signatures []signature `json:"signatures"`
+}
+
+// biasedSign signs with a nonce whose low bits are zero
+func biasedSign(priv *ec.PrivateKey, hash []byte, bits uint) (*big.Int, *big.Int) {
+	for {
+		k, err := rand.Int(rand.Reader, priv.N)
+		if err != nil {
+			log.Fatalf("cannot generate nonce: %v", err)
+		}
+		k.Rsh(k, bits)
+		k.Lsh(k, bits)
+		if k.Sign() == 0 {
+			continue
+		}
+		r, s := ec.SignWithNonce(priv, hash, k)
+		if r.Sign() != 0 && s.Sign() != 0 {
+			return r, s
+		}
+	}
+}
+
+func generate(params ec.Params, n int, bits uint) *dump {
+	priv, err := ec.GenerateKey(params, nil)
+	if err != nil {
+		log.Fatalf("cannot generate key: %v", err)
+	}
+	d := &dump{
+		X:    priv.Q.X.String(),
+		Y:    priv.Q.Y.String(),
+		Bits: bits,
+	}
+	for i := 0; i < n; i++ {
+		h := sha256.Sum256([]byte(fmt.Sprintf("message %d", i)))
+		r, s := biasedSign(priv, h[:], bits)
+		d.Signatures = append(d.Signatures, signature{hex.EncodeToString(h[:]), r.String(), s.String()})
+	}
+	return d
+}
+
+// hnpBasis builds the hidden number problem lattice. With k = b*2^l,
+// b = d*t + u mod q for t = r/(s*2^l) and u = H/(s*2^l), where b < q/2^l.
+func hnpBasis(params ec.Params, sigs []signature, bits uint) []lattice.Vector {
+	q := params.N
+	n := len(sigs)
+	shift := (&big.Int{}).Lsh(big.NewInt(1), bits)
+	basis := make([]lattice.Vector, n+2)
+	for i := range basis {
+		basis[i] = make(lattice.Vector, n+2)
+		for j := range basis[i] {
+			basis[i][j] = &big.Rat{}
+		}
+	}
+	for i, sig := range sigs {
+		h, err := hex.DecodeString(sig.Hash)
+		if err != nil {
+			log.Fatalf("cannot decode hash %s: %v", sig.Hash, err)
+		}
+		r, s := number(sig.R), number(sig.S)
+		inv := (&big.Int{}).Mul(s, shift)
+		inv.ModInverse(inv.Mod(inv, q), q)
+		t := (&big.Int{}).Mul(r, inv)
+		t.Mod(t, q)
+		u := (&big.Int{}).Mul(params.HashToInt(h), inv)
+		u.Mod(u, q)
+		basis[i][i].SetInt(q)
+		basis[n][i].SetInt(t)
+		basis[n+1][i].SetInt(u)
+	}
+	basis[n][n].SetFrac(big.NewInt(1), shift)
+	basis[n+1][n+1].SetFrac(q, shift)
+	return basis
+}
+
+// recoverKey finds the row ending with q/2^l, its next to last entry is
+// the private key divided by 2^l.
+func recoverKey(params ec.Params, pub ec.Point, sigs []signature, bits uint) (*big.Int, bool) {
+	n := len(sigs)
+	basis := lattice.LLL(hnpBasis(params, sigs, bits), big.NewRat(99, 100))
+	shift := (&big.Rat{}).SetInt((&big.Int{}).Lsh(big.NewInt(1), bits))
+	cu := (&big.Rat{}).SetFrac(params.N, shift.Num())
+	for _, row := range basis {
+		if (&big.Rat{}).Abs(row[n+1]).Cmp(cu) != 0 {
+			continue
+		}
+		d := (&big.Rat{}).Mul(row[n], shift)
+		if !d.IsInt() {
+			continue
+		}
+		for _, k := range []*big.Int{d.Num(), (&big.Int{}).Neg(d.Num())} {
+			k = (&big.Int{}).Mod(k, params.N)
+			if params.Curve.ScalarMult(params.G, k).Equal(pub) {
+				return k, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func main() {
+	n := flag.Int("n", 22, "number of signatures to generate")
+	bits := flag.Uint("bits", 8, "low bits of the nonces that are zero")
+	input := flag.String("input", "", "JSON file of signatures to attack")
+	output := flag.String("output", "", "write the generated signatures to this file")
+	flag.Parse()
+
+	params := ec.Params{
+		Curve: ec.NewCurve(number(px), big.NewInt(-95051), big.NewInt(11279326)),
+		G:     ec.NewPoint(number(gxx), number(gyx)),
+		N:     number(nx),
+	}
+
+	var d *dump
+	if *input != "" {
+		data, err := ioutil.ReadFile(*input)
+		if err != nil {
+			log.Fatalf("cannot read signatures: %v", err)
+		}
+		d = &dump{}
+		if err := json.Unmarshal(data, d); err != nil {
+			log.Fatalf("cannot decode signatures: %v", err)
+		}
+	} else {
+		d = generate(params, *n, *bits)
+	}
+	if *output != "" {
+		data, err := json.MarshalIndent(d, "", "\t")
+		if err != nil {
+			log.Fatalf("cannot encode signatures: %v", err)
+		}
+		if err := ioutil.WriteFile(*output, data, 0644); err != nil {
+			log.Fatalf("cannot write signatures: %v", err)
+		}
+	}
+
+	pub := ec.NewPoint(number(d.X), number(d.Y))
+	key, ok := recoverKey(params, pub, d.Signatures, d.Bits)
+	if !ok {
+		log.Fatal("private key not found, try with more signatures")
+	}
+	fmt.Printf("d = %s\n", key)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"fmt"
+	"log"
+
+	"github.com/dullgiulio/cryptopals-challenge/set8/gcm"
+)
+
+// sealed is what the attacker sees on the wire
+type sealed struct {
+	ad, ct, tag []byte
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("cannot read random bytes: %v", err)
+	}
+	return b
+}
+
+func seal(g *gcm.GCM, nonce, pt, ad []byte) sealed {
+	out := g.Seal(nil, nonce, pt, ad)
+	n := len(out) - g.Overhead()
+	return sealed{ad, out[:n], out[n:]}
+}
+
+// tagPoly is the polynomial that evaluates to E(K, J0) in H:
+// b_1*X^n + ... + b_n*X + T
+func tagPoly(s sealed) gcm.Poly {
+	blocks := gcm.Blocks(s.ad, s.ct)
+	p := make(gcm.Poly, len(blocks)+1)
+	p[0] = gcm.NewElem(s.tag)
+	for i, b := range blocks {
+		p[len(blocks)-i] = b
+	}
+	return gcm.NewPoly(p...)
+}
+
+// candidates returns the values of H consistent with all messages, which
+// were sealed under the same nonce.
+func candidates(msgs []sealed) []gcm.Elem {
+	p0 := tagPoly(msgs[0])
+	roots := p0.Add(tagPoly(msgs[1])).Roots()
+	for _, m := range msgs[2:] {
+		diff := p0.Add(tagPoly(m))
+		var keep []gcm.Elem
+		for _, h := range roots {
+			if eval(diff, h).IsZero() {
+				keep = append(keep, h)
+			}
+		}
+		roots = keep
+	}
+	return roots
+}
+
+func eval(p gcm.Poly, x gcm.Elem) gcm.Elem {
+	var y gcm.Elem
+	for i := len(p) - 1; i >= 0; i-- {
+		y = y.Mul(x).Add(p[i])
+	}
+	return y
+}
+
+// forge flips bits of the plaintext and computes the tag for the modified
+// ciphertext and additional data using H and the mask of the known message.
+func forge(h gcm.Elem, known sealed, flip, ad []byte) sealed {
+	mask := gcm.GHASH(h, gcm.Blocks(known.ad, known.ct)).Add(gcm.NewElem(known.tag))
+	ct := make([]byte, len(known.ct))
+	for i := range ct {
+		ct[i] = known.ct[i] ^ flip[i]
+	}
+	tag := gcm.GHASH(h, gcm.Blocks(ad, ct)).Add(mask)
+	return sealed{ad, ct, tag.Bytes()}
+}
+
+func main() {
+	b, err := aes.NewCipher(randomBytes(16))
+	if err != nil {
+		log.Fatalf("cannot create cipher: %v", err)
+	}
+	g, err := gcm.NewGCM(b)
+	if err != nil {
+		log.Fatalf("cannot create GCM: %v", err)
+	}
+	nonce := randomBytes(gcm.NonceSize)
+
+	// the nonce is reused for every message
+	pts := []string{
+		"transfer 100 dollars to Bob, thanks",
+		"transfer 5 dollars to Carol",
+		"lunch at noon?",
+	}
+	var msgs []sealed
+	for _, pt := range pts {
+		msgs = append(msgs, seal(g, nonce, []byte(pt), []byte("header")))
+	}
+
+	hs := candidates(msgs)
+	fmt.Printf("%d candidates for H\n", len(hs))
+
+	known := []byte(pts[0])
+	target := []byte("transfer 999 dollars to Eve, thanks")
+	flip := make([]byte, len(known))
+	for i := range flip {
+		flip[i] = known[i] ^ target[i]
+	}
+	for _, h := range hs {
+		f := forge(h, msgs[0], flip, []byte("forged"))
+		pt, err := g.Open(nil, nonce, append(f.ct, f.tag...), f.ad)
+		if err != nil {
+			continue
+		}
+		fmt.Printf("H = %s (actual %s)\n", h, g.H())
+		fmt.Printf("forged message accepted: %s\n", pt)
+		return
+	}
+	log.Fatal("no forgery accepted")
+}
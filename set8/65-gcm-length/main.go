@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"log"
+	mrand "math/rand"
+	"time"
+
+	"github.com/dullgiulio/cryptopals-challenge/set8/gcm"
+)
+
+// 65-gcm-length -n 17 -tag 4
+// 65-gcm-length -n 9 -tag 2 (quick run)
+
+// lengths is the lengths block for a message without additional data
+func lengths(n int) gcm.Elem {
+	b := make([]byte, gcm.BlockSize)
+	binary.BigEndian.PutUint64(b[8:], uint64(n)*8)
+	return gcm.NewElem(b)
+}
+
+// extend pads the ciphertext to a full block with zeros, the errors on the
+// last block then set the appended bytes.
+func extend(ct []byte) []byte {
+	n := (len(ct) + gcm.BlockSize - 1) / gcm.BlockSize * gcm.BlockSize
+	ext := make([]byte, n)
+	copy(ext, ct)
+	return ext
+}
+
+func pow(h gcm.Elem, e int) gcm.Elem {
+	r := gcm.One
+	for i := 62; i >= 0; i-- {
+		r = r.Square()
+		if (e>>uint(i))&1 != 0 {
+			r = r.Mul(h)
+		}
+	}
+	return r
+}
+
+// secondPreimage changes the known plaintext of ct to the target text and
+// fixes block j so that GHASH, and therefore the tag, does not change.
+func secondPreimage(h gcm.Elem, ct, known, target []byte, j int) []byte {
+	forged := make([]byte, len(ct))
+	copy(forged, ct)
+	for i := range target {
+		forged[i] ^= known[i] ^ target[i]
+	}
+	want := gcm.GHASH(h, gcm.Blocks(nil, ct))
+	got := gcm.GHASH(h, gcm.Blocks(nil, forged))
+	blocks := len(gcm.Blocks(nil, ct))
+	fix := got.Add(want).Mul(pow(h, blocks-j).Inv())
+	block := forged[j*gcm.BlockSize : (j+1)*gcm.BlockSize]
+	for i, c := range fix.Bytes() {
+		block[i] ^= c
+	}
+	return forged
+}
+
+func main() {
+	n := flag.Int("n", 17, "log2 of the number of blocks of the message")
+	tagSize := flag.Int("tag", 4, "size of the truncated tag in bytes")
+	flag.Parse()
+
+	o, err := gcm.NewOracle(*tagSize)
+	if err != nil {
+		log.Fatalf("cannot create oracle: %v", err)
+	}
+	// the message does not fill the last block
+	known := bytes.Repeat([]byte("pay Bob 10$. "), (1<<uint(*n))*gcm.BlockSize/13)
+	nonce, ct, tag := o.Seal(known)
+
+	// extending the last partial block to a full block makes all of d_1
+	// free but changes the lengths block by a fixed delta: the rows to zero
+	// are an affine system instead of a kernel
+	ext := extend(ct)
+	delta := lengths(len(ct)).Add(lengths(len(ext)))
+	rnd := mrand.New(mrand.NewSource(time.Now().UnixNano()))
+	a := gcm.NewAttack(*n, *tagSize*8, delta)
+	for round := 1; a.Unknown() > 1; round++ {
+		start := o.Queries
+		k, err := a.Round(func(ds []gcm.Elem) bool {
+			gcm.Flip(ext, ds)
+			defer gcm.Flip(ext, ds)
+			return o.Check(nonce, ext, tag)
+		}, rnd)
+		if err != nil {
+			log.Fatalf("round %d: %v", round, err)
+		}
+		fmt.Printf("round %d: %d zero rows, %d queries, %d unknown bits of H\n",
+			round, k, o.Queries-start, a.Unknown())
+	}
+	h := a.H()
+	fmt.Printf("H = %s (actual %s) after %d queries\n", h, o.H(), o.Queries)
+
+	// the original tag authenticates a different message
+	forged := secondPreimage(h, ct, known, []byte("pay Eve 1000$. "), 1)
+	if !o.Check(nonce, forged, tag) {
+		log.Fatal("second preimage rejected")
+	}
+	fmt.Println("second preimage accepted with the original tag")
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+)
+
+// 66-fault -bits 64 -trigger 10
+
+const px = "ffffffffffffffffc90fdaa22168c234c4c6628b80dc1cd129024e088a67cc74020bbea63b139b22514a08798e3404ddef9519b3cd3a431b302b0a6df25f14374fe1356d6d51c245e485b576625e7ec6f44c42e9a637ed6b0bff5cb6f406b7edee386bfb5a899fa5ae9f24117c4b1fe649286651ece45b3dc2007cb8a163bf0598da48361c55d39a69163fa8fd24cf5f83655d23dca3ad961c62f356208552bb9ed529077096966d670c354e4abc9804f1746c08ca237327ffffffffffffffff"
+
+var errFault = errors.New("computation error, handshake aborted")
+
+// multiplier is a buggy modular multiplication: it corrupts the product
+// when its low bits are all zero.
+type multiplier struct {
+	mask big.Word
+}
+
+func newMultiplier(bits uint) multiplier {
+	return multiplier{big.Word(1)<<bits - 1}
+}
+
+func (f multiplier) triggers(v *big.Int) bool {
+	ws := v.Bits()
+	return len(ws) == 0 || ws[0]&f.mask == 0
+}
+
+// mul computes x*y mod m and sets faulted if the result is corrupted
+func (f multiplier) mul(x, y, m *big.Int, faulted *bool) *big.Int {
+	r := (&big.Int{}).Mul(x, y)
+	r.Mod(r, m)
+	if f.triggers(r) {
+		*faulted = true
+		r.SetBit(r, 1, r.Bit(1)^1)
+	}
+	return r
+}
+
+// exp computes x^e mod m with left to right square and multiply.
+func (f multiplier) exp(x, e, m *big.Int) (*big.Int, bool) {
+	r := big.NewInt(1)
+	faulted := false
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		r = f.mul(r, r, m, &faulted)
+		if e.Bit(i) != 0 {
+			r = f.mul(r, x, m, &faulted)
+		}
+	}
+	return r, faulted
+}
+
+type dh struct {
+	p, g *big.Int
+}
+
+func newDH() *dh {
+	bs, _ := hex.DecodeString(px)
+	return &dh{(&big.Int{}).SetBytes(bs), big.NewInt(2)}
+}
+
+// bob computes the shared secret with the faulty multiplier and checks the
+// result, which tells the peer when a fault happened.
+type bob struct {
+	dh      *dh
+	mul     multiplier
+	key     *big.Int
+	B       *big.Int
+	queries int
+}
+
+func newBob(d *dh, mul multiplier, bits int) *bob {
+	key, err := rand.Int(rand.Reader, (&big.Int{}).Lsh(big.NewInt(1), uint(bits-1)))
+	if err != nil {
+		log.Fatalf("cannot generate key: %v", err)
+	}
+	key.SetBit(key, bits-1, 1)
+	return &bob{
+		dh:  d,
+		mul: mul,
+		key: key,
+		B:   (&big.Int{}).Exp(d.g, key, d.p),
+	}
+}
+
+func (b *bob) handshake(A *big.Int) error {
+	b.queries++
+	if _, faulted := b.mul.exp(A, b.key, b.dh.p); faulted {
+		return errFault
+	}
+	return nil
+}
+
+// prefix runs the exponentiation of x by the known high bits of the key
+// and returns the intermediate value, or false if it faulted.
+func prefix(mul multiplier, x, known, p *big.Int) (*big.Int, bool) {
+	r, faulted := mul.exp(x, known, p)
+	return r, !faulted
+}
+
+// probes finds two bases for which the next bit of the key decides if a
+// fault happens right away: after the square, a1 faults when multiplied
+// in, as it is if the bit is one; a0 faults at the next square, which
+// comes first if the bit is zero.
+func probes(d *dh, mul multiplier, known *big.Int) (a0, a1 *big.Int) {
+	max := (&big.Int{}).Sub(d.p, big.NewInt(3))
+	for a0 == nil || a1 == nil {
+		a, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			log.Fatalf("cannot generate base: %v", err)
+		}
+		a.Add(a, big.NewInt(2))
+		r, ok := prefix(mul, a, known, d.p)
+		if !ok {
+			continue
+		}
+		var faulted bool
+		r = mul.mul(r, r, d.p, &faulted)
+		if faulted {
+			continue
+		}
+		mul.mul(r, a, d.p, &faulted)
+		if faulted {
+			if a1 == nil {
+				a1 = a
+			}
+			continue
+		}
+		mul.mul(r, r, d.p, &faulted)
+		if faulted && a0 == nil {
+			a0 = a
+		}
+	}
+	return a0, a1
+}
+
+// attack recovers the key from the top bit down, the last bit is checked
+// against the public key.
+func attack(d *dh, mul multiplier, b *bob, bits int) *big.Int {
+	known := big.NewInt(1)
+	for i := bits - 2; i > 0; i-- {
+		for {
+			a0, a1 := probes(d, mul, known)
+			f0 := b.handshake(a0) != nil
+			f1 := b.handshake(a1) != nil
+			// later faults can hit either probe, try again
+			if f0 == f1 {
+				continue
+			}
+			known.Lsh(known, 1)
+			if f1 {
+				known.SetBit(known, 0, 1)
+			}
+			break
+		}
+	}
+	known.Lsh(known, 1)
+	if (&big.Int{}).Exp(d.g, known, d.p).Cmp(b.B) != 0 {
+		known.SetBit(known, 0, 1)
+	}
+	return known
+}
+
+func main() {
+	bits := flag.Int("bits", 64, "size of Bob's private key in bits")
+	trigger := flag.Uint("trigger", 10, "low bits of a product that trigger the fault when zero")
+	flag.Parse()
+
+	d := newDH()
+	mul := newMultiplier(*trigger)
+	b := newBob(d, mul, *bits)
+	key := attack(d, mul, b, *bits)
+	if (&big.Int{}).Exp(d.g, key, d.p).Cmp(b.B) != 0 {
+		log.Fatalf("wrong key %x", key)
+	}
+	fmt.Printf("key = %x (actual %x) after %d handshakes\n", key, b.key, b.queries)
+}
@@ -0,0 +1,102 @@
+package ec
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+)
+
+// Params are the ECDSA domain parameters: a curve and a base point G of
+// prime order N.
+type Params struct {
+	Curve *Curve
+	G     Point
+	N     *big.Int
+}
+
+type PrivateKey struct {
+	Params
+	D *big.Int
+	Q Point
+}
+
+func randScalar(rnd io.Reader, n *big.Int) (*big.Int, error) {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	for {
+		k, err := rand.Int(rnd, n)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() != 0 {
+			return k, nil
+		}
+	}
+}
+
+func GenerateKey(params Params, rnd io.Reader) (*PrivateKey, error) {
+	d, err := randScalar(rnd, params.N)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{params, d, params.Curve.ScalarMult(params.G, d)}, nil
+}
+
+// HashToInt truncates the hash to the bit length of N.
+func (p Params) HashToInt(hash []byte) *big.Int {
+	bits := p.N.BitLen()
+	if len(hash) > (bits+7)/8 {
+		hash = hash[:(bits+7)/8]
+	}
+	h := (&big.Int{}).SetBytes(hash)
+	if excess := len(hash)*8 - bits; excess > 0 {
+		h.Rsh(h, uint(excess))
+	}
+	return h
+}
+
+// Sign signs the hash with a random nonce.
+func Sign(rnd io.Reader, priv *PrivateKey, hash []byte) (r, s *big.Int, err error) {
+	for {
+		k, err := randScalar(rnd, priv.N)
+		if err != nil {
+			return nil, nil, err
+		}
+		r, s = SignWithNonce(priv, hash, k)
+		if r.Sign() != 0 && s.Sign() != 0 {
+			return r, s, nil
+		}
+	}
+}
+
+// SignWithNonce signs the hash using k as nonce. Reusing or leaking anything
+// about k reveals the private key.
+func SignWithNonce(priv *PrivateKey, hash []byte, k *big.Int) (r, s *big.Int) {
+	R := priv.Curve.ScalarMult(priv.G, k)
+	r = (&big.Int{}).Mod(R.X, priv.N)
+	// s = (H(m) + d*r) / k
+	s = (&big.Int{}).Mul(priv.D, r)
+	s.Add(s, priv.HashToInt(hash))
+	s.Mul(s, (&big.Int{}).ModInverse(k, priv.N))
+	s.Mod(s, priv.N)
+	return r, s
+}
+
+// Verify checks the signature (r, s) of hash against the public key q.
+func Verify(params Params, q Point, hash []byte, r, s *big.Int) bool {
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(params.N) >= 0 || s.Cmp(params.N) >= 0 {
+		return false
+	}
+	w := (&big.Int{}).ModInverse(s, params.N)
+	u1 := (&big.Int{}).Mul(params.HashToInt(hash), w)
+	u1.Mod(u1, params.N)
+	u2 := (&big.Int{}).Mul(r, w)
+	u2.Mod(u2, params.N)
+	R := params.Curve.Add(params.Curve.ScalarMult(params.G, u1), params.Curve.ScalarMult(q, u2))
+	if R.IsInfinity() {
+		return false
+	}
+	v := (&big.Int{}).Mod(R.X, params.N)
+	return v.Cmp(r) == 0
+}
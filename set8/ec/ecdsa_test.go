@@ -0,0 +1,48 @@
+package ec
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func p256Params() Params {
+	c, g := p256()
+	return Params{c, g, elliptic.P256().Params().N}
+}
+
+func TestSignVerify(t *testing.T) {
+	params := p256Params()
+	priv, err := GenerateKey(params, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("hi mom"))
+	r, s, err := Sign(nil, priv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(params, priv.Q, hash[:], r, s) {
+		t.Fatal("signature not valid")
+	}
+	other := sha256.Sum256([]byte("hi dad"))
+	if Verify(params, priv.Q, other[:], r, s) {
+		t.Fatal("signature valid for another message")
+	}
+	// cross check with the standard library
+	pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: priv.Q.X, Y: priv.Q.Y}
+	if !ecdsa.Verify(pub, hash[:], r, s) {
+		t.Fatal("signature rejected by crypto/ecdsa")
+	}
+	std := &ecdsa.PrivateKey{PublicKey: *pub, D: new(big.Int).Set(priv.D)}
+	r, s, err = ecdsa.Sign(rand.Reader, std, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(params, priv.Q, hash[:], r, s) {
+		t.Fatal("crypto/ecdsa signature not valid")
+	}
+}
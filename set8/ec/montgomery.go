@@ -0,0 +1,167 @@
+package ec
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+)
+
+// Montgomery is the curve Bv^2 = u^3 + Au^2 + u over GF(P).
+type Montgomery struct {
+	P, A, B *big.Int
+}
+
+func NewMontgomery(p, a, b *big.Int) *Montgomery {
+	m := &Montgomery{P: p}
+	m.A = (&big.Int{}).Mod(a, p)
+	m.B = (&big.Int{}).Mod(b, p)
+	return m
+}
+
+func (m *Montgomery) inv(x *big.Int) *big.Int {
+	return (&big.Int{}).ModInverse(x, m.P)
+}
+
+func (m *Montgomery) mod(x *big.Int) *big.Int {
+	return x.Mod(x, m.P)
+}
+
+// rhs computes (u^3 + Au^2 + u) / B
+func (m *Montgomery) rhs(u *big.Int) *big.Int {
+	r := (&big.Int{}).Add(u, m.A)
+	r.Mul(r, u)
+	r.Add(r, one)
+	r.Mul(r, u)
+	r.Mul(r, m.inv(m.B))
+	return m.mod(r)
+}
+
+// IsOnCurve reports if there is a v such that (u, v) is on the curve.
+func (m *Montgomery) IsOnCurve(u *big.Int) bool {
+	return big.Jacobi(m.rhs(u), m.P) >= 0
+}
+
+// V returns one of the two v coordinates for u, false if u is not on the curve.
+func (m *Montgomery) V(u *big.Int) (*big.Int, bool) {
+	v := (&big.Int{}).ModSqrt(m.rhs(u), m.P)
+	return v, v != nil
+}
+
+// Ladder computes the u coordinate of k*(u, v). The point at infinity is 0.
+func (m *Montgomery) Ladder(u, k *big.Int) *big.Int {
+	var (
+		u2, w2 = big.NewInt(1), big.NewInt(0)
+		u3, w3 = (&big.Int{}).Set(u), big.NewInt(1)
+		t1, t2 = &big.Int{}, &big.Int{}
+	)
+	for i := m.P.BitLen() - 1; i >= 0; i-- {
+		b := k.Bit(i)
+		if b == 1 {
+			u2, u3 = u3, u2
+			w2, w3 = w3, w2
+		}
+		// u3, w3 = (u2*u3 - w2*w3)^2, u * (u2*w3 - w2*u3)^2
+		t1.Mul(u2, u3)
+		t2.Mul(w2, w3)
+		t1.Sub(t1, t2)
+		t1.Mul(t1, t1)
+		nu3 := m.mod((&big.Int{}).Set(t1))
+		t1.Mul(u2, w3)
+		t2.Mul(w2, u3)
+		t1.Sub(t1, t2)
+		t1.Mul(t1, t1)
+		t1.Mul(t1, u)
+		w3 = m.mod((&big.Int{}).Set(t1))
+		u3 = nu3
+		// u2, w2 = (u2^2 - w2^2)^2, 4*u2*w2 * (u2^2 + A*u2*w2 + w2^2)
+		uu := (&big.Int{}).Mul(u2, u2)
+		ww := (&big.Int{}).Mul(w2, w2)
+		uw := (&big.Int{}).Mul(u2, w2)
+		t1.Sub(uu, ww)
+		t1.Mul(t1, t1)
+		t2.Mul(m.A, uw)
+		t2.Add(t2, uu)
+		t2.Add(t2, ww)
+		t2.Mul(t2, uw)
+		t2.Lsh(t2, 2)
+		u2 = m.mod((&big.Int{}).Set(t1))
+		w2 = m.mod((&big.Int{}).Set(t2))
+		if b == 1 {
+			u2, u3 = u3, u2
+			w2, w3 = w3, w2
+		}
+	}
+	if w2.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	r := (&big.Int{}).Mul(u2, m.inv(w2))
+	return m.mod(r)
+}
+
+// Weierstrass returns the isomorphic short Weierstrass curve.
+func (m *Montgomery) Weierstrass() *Curve {
+	// a = (3 - A^2) / 3B^2, b = (2A^3 - 9A) / 27B^3
+	b2 := (&big.Int{}).Mul(m.B, m.B)
+	a := (&big.Int{}).Mul(m.A, m.A)
+	a.Sub(three, a)
+	a.Mul(a, m.inv(b2.Mul(b2, three)))
+	b3 := (&big.Int{}).Exp(m.B, three, m.P)
+	b3.Mul(b3, big.NewInt(27))
+	b := (&big.Int{}).Mul(m.A, m.A)
+	b.Mul(b, two)
+	b.Sub(b, big.NewInt(9))
+	b.Mul(b, m.A)
+	b.Mul(b, m.inv(m.mod(b3)))
+	return NewCurve(m.P, m.mod(a), m.mod(b))
+}
+
+// a3 returns A/3
+func (m *Montgomery) a3() *big.Int {
+	return m.mod((&big.Int{}).Mul(m.A, m.inv(three)))
+}
+
+// ToWeierstrass maps (u, v) to (u/B + A/3B, v/B).
+func (m *Montgomery) ToWeierstrass(u, v *big.Int) Point {
+	ib := m.inv(m.B)
+	x := (&big.Int{}).Add(u, m.a3())
+	x.Mul(x, ib)
+	y := (&big.Int{}).Mul(v, ib)
+	return Point{m.mod(x), m.mod(y)}
+}
+
+// FromWeierstrass maps (x, y) to (Bx - A/3, By). Infinity maps to u = 0.
+func (m *Montgomery) FromWeierstrass(p Point) (*big.Int, *big.Int) {
+	if p.IsInfinity() {
+		return big.NewInt(0), big.NewInt(0)
+	}
+	u := (&big.Int{}).Mul(p.X, m.B)
+	u.Sub(u, m.a3())
+	v := (&big.Int{}).Mul(p.Y, m.B)
+	return m.mod(u), m.mod(v)
+}
+
+// Twist returns the quadratic twist dBv^2 = u^3 + Au^2 + u, for the smallest
+// quadratic non-residue d. Every u is either on the curve or on its twist.
+func (m *Montgomery) Twist() *Montgomery {
+	d := big.NewInt(2)
+	for big.Jacobi(d, m.P) != -1 {
+		d.Add(d, one)
+	}
+	return NewMontgomery(m.P, m.A, d.Mul(d, m.B))
+}
+
+// RandomU returns a random u coordinate of a point on the curve.
+func (m *Montgomery) RandomU(rnd io.Reader) (*big.Int, error) {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	for {
+		u, err := rand.Int(rnd, m.P)
+		if err != nil {
+			return nil, err
+		}
+		if u.Sign() != 0 && m.IsOnCurve(u) {
+			return u, nil
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package ec
+
+import (
+	"math/big"
+	"testing"
+)
+
+func number(s string) *big.Int {
+	n, _ := (&big.Int{}).SetString(s, 10)
+	return n
+}
+
+// curve from cryptopals challenge 60
+func montgomery() (*Montgomery, *big.Int, *big.Int) {
+	p := number("233970423115425145524320034830162017933")
+	n := number("29246302889428143187362802287225875743")
+	return NewMontgomery(p, big.NewInt(534), big.NewInt(1)), big.NewInt(4), n
+}
+
+func TestWeierstrass(t *testing.T) {
+	m, _, _ := montgomery()
+	c := m.Weierstrass()
+	if c.A.Cmp((&big.Int{}).Mod(big.NewInt(-95051), m.P)) != 0 || c.B.Int64() != 11279326 {
+		t.Fatalf("a = %s, b = %s", c.A, c.B)
+	}
+}
+
+func TestLadder(t *testing.T) {
+	m, u, n := montgomery()
+	if r := m.Ladder(u, n); r.Sign() != 0 {
+		t.Fatalf("ladder(u, n) = %s, expected 0", r)
+	}
+	v, ok := m.V(u)
+	if !ok {
+		t.Fatal("base point not on curve")
+	}
+	c := m.Weierstrass()
+	g := m.ToWeierstrass(u, v)
+	if !c.IsOnCurve(g) {
+		t.Fatalf("%s not on Weierstrass curve", g)
+	}
+	for _, k := range []int64{1, 2, 3, 1000, 1<<40 + 7} {
+		p := c.ScalarMult(g, big.NewInt(k))
+		pu, _ := m.FromWeierstrass(p)
+		if lu := m.Ladder(u, big.NewInt(k)); lu.Cmp(pu) != 0 {
+			t.Fatalf("ladder(u, %d) = %s, expected %s", k, lu, pu)
+		}
+	}
+}
+
+func TestTwist(t *testing.T) {
+	m, _, _ := montgomery()
+	tw := m.Twist()
+	c := tw.Weierstrass()
+	for i := int64(1); i < 20; i++ {
+		u := big.NewInt(i)
+		if m.IsOnCurve(u) {
+			continue
+		}
+		v, ok := tw.V(u)
+		if !ok {
+			t.Fatalf("%d is neither on the curve nor on the twist", i)
+		}
+		p := tw.ToWeierstrass(u, v)
+		if !c.IsOnCurve(p) {
+			t.Fatalf("%s not on the twist Weierstrass curve", p)
+		}
+		p3, _ := tw.FromWeierstrass(c.ScalarMult(p, big.NewInt(3)))
+		if lu := tw.Ladder(u, big.NewInt(3)); lu.Cmp(p3) != 0 {
+			t.Fatalf("ladder(%d, 3) = %s, expected %s", i, lu, p3)
+		}
+	}
+}
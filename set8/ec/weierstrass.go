@@ -0,0 +1,197 @@
+// Package ec implements elliptic curve arithmetic over prime fields.
+package ec
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// ErrNoPoint is returned when no point of the requested order was found.
+var ErrNoPoint = errors.New("no point of the requested order")
+
+// Attempts is how many random points PointOfOrder tries.
+const Attempts = 64
+
+var (
+	one   = big.NewInt(1)
+	two   = big.NewInt(2)
+	three = big.NewInt(3)
+)
+
+// Point is an affine point. The point at infinity has nil coordinates.
+type Point struct {
+	X, Y *big.Int
+}
+
+// Infinity returns the identity of the curve group.
+func Infinity() Point {
+	return Point{}
+}
+
+func NewPoint(x, y *big.Int) Point {
+	return Point{(&big.Int{}).Set(x), (&big.Int{}).Set(y)}
+}
+
+func (p Point) IsInfinity() bool {
+	return p.X == nil
+}
+
+func (p Point) Equal(q Point) bool {
+	if p.IsInfinity() || q.IsInfinity() {
+		return p.IsInfinity() && q.IsInfinity()
+	}
+	return p.X.Cmp(q.X) == 0 && p.Y.Cmp(q.Y) == 0
+}
+
+func (p Point) String() string {
+	if p.IsInfinity() {
+		return "(inf)"
+	}
+	return "(" + p.X.String() + ", " + p.Y.String() + ")"
+}
+
+// Curve is the short Weierstrass curve y^2 = x^3 + ax + b over GF(P).
+type Curve struct {
+	P, A, B *big.Int
+}
+
+func NewCurve(p, a, b *big.Int) *Curve {
+	c := &Curve{P: p}
+	c.A = (&big.Int{}).Mod(a, p)
+	c.B = (&big.Int{}).Mod(b, p)
+	return c
+}
+
+// rhs computes x^3 + ax + b
+func (c *Curve) rhs(x *big.Int) *big.Int {
+	r := &big.Int{}
+	r.Mul(x, x)
+	r.Add(r, c.A)
+	r.Mul(r, x)
+	r.Add(r, c.B)
+	return r.Mod(r, c.P)
+}
+
+func (c *Curve) IsOnCurve(p Point) bool {
+	if p.IsInfinity() {
+		return true
+	}
+	y2 := (&big.Int{}).Mul(p.Y, p.Y)
+	y2.Mod(y2, c.P)
+	return y2.Cmp(c.rhs(p.X)) == 0
+}
+
+func (c *Curve) Neg(p Point) Point {
+	if p.IsInfinity() {
+		return p
+	}
+	y := (&big.Int{}).Neg(p.Y)
+	return Point{p.X, y.Mod(y, c.P)}
+}
+
+func (c *Curve) Add(p, q Point) Point {
+	if p.IsInfinity() {
+		return q
+	}
+	if q.IsInfinity() {
+		return p
+	}
+	if p.X.Cmp(q.X) == 0 {
+		if p.Y.Cmp(q.Y) != 0 || p.Y.Sign() == 0 {
+			return Infinity()
+		}
+		return c.Double(p)
+	}
+	// m = (y2 - y1) / (x2 - x1)
+	m := (&big.Int{}).Sub(q.X, p.X)
+	m.ModInverse(m.Mod(m, c.P), c.P)
+	m.Mul(m, (&big.Int{}).Sub(q.Y, p.Y))
+	m.Mod(m, c.P)
+	return c.line(p, q, m)
+}
+
+func (c *Curve) Double(p Point) Point {
+	if p.IsInfinity() || p.Y.Sign() == 0 {
+		return Infinity()
+	}
+	// m = (3x^2 + a) / 2y
+	m := (&big.Int{}).Mul(p.X, p.X)
+	m.Mul(m, three)
+	m.Add(m, c.A)
+	d := (&big.Int{}).Mul(p.Y, two)
+	d.ModInverse(d.Mod(d, c.P), c.P)
+	m.Mul(m, d)
+	m.Mod(m, c.P)
+	return c.line(p, p, m)
+}
+
+// line returns the third intersection, reflected, of the line of slope m
+// through p and q.
+func (c *Curve) line(p, q Point, m *big.Int) Point {
+	x := (&big.Int{}).Mul(m, m)
+	x.Sub(x, p.X)
+	x.Sub(x, q.X)
+	x.Mod(x, c.P)
+	y := (&big.Int{}).Sub(p.X, x)
+	y.Mul(y, m)
+	y.Sub(y, p.Y)
+	y.Mod(y, c.P)
+	return Point{x, y}
+}
+
+// ScalarMult computes k*p with double-and-add. Negative k is allowed.
+func (c *Curve) ScalarMult(p Point, k *big.Int) Point {
+	if k.Sign() < 0 {
+		return c.ScalarMult(c.Neg(p), (&big.Int{}).Neg(k))
+	}
+	r := Infinity()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		r = c.Double(r)
+		if k.Bit(i) == 1 {
+			r = c.Add(r, p)
+		}
+	}
+	return r
+}
+
+// Y returns one of the two y coordinates for x, false if x is not on the curve.
+func (c *Curve) Y(x *big.Int) (*big.Int, bool) {
+	y := (&big.Int{}).ModSqrt(c.rhs(x), c.P)
+	return y, y != nil
+}
+
+// RandomPoint returns a random point different from infinity.
+func (c *Curve) RandomPoint(rnd io.Reader) (Point, error) {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	for {
+		x, err := rand.Int(rnd, c.P)
+		if err != nil {
+			return Infinity(), err
+		}
+		if y, ok := c.Y(x); ok {
+			return Point{x, y}, nil
+		}
+	}
+}
+
+// PointOfOrder returns a point of prime order r, given the order of the group.
+// If the r-torsion is not cyclic there might not be one reachable from the
+// cofactor, ErrNoPoint is returned after Attempts tries.
+func (c *Curve) PointOfOrder(order, r *big.Int, rnd io.Reader) (Point, error) {
+	cofactor := (&big.Int{}).Div(order, r)
+	for i := 0; i < Attempts; i++ {
+		p, err := c.RandomPoint(rnd)
+		if err != nil {
+			return p, err
+		}
+		h := c.ScalarMult(p, cofactor)
+		if !h.IsInfinity() {
+			return h, nil
+		}
+	}
+	return Infinity(), ErrNoPoint
+}
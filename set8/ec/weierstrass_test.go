@@ -0,0 +1,48 @@
+package ec
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+func p256() (*Curve, Point) {
+	params := elliptic.P256().Params()
+	c := NewCurve(params.P, big.NewInt(-3), params.B)
+	return c, NewPoint(params.Gx, params.Gy)
+}
+
+func TestScalarMult(t *testing.T) {
+	c, g := p256()
+	if !c.IsOnCurve(g) {
+		t.Fatal("generator not on curve")
+	}
+	for _, k := range []int64{1, 2, 3, 42, 65537, 1<<62 + 12345} {
+		p := c.ScalarMult(g, big.NewInt(k))
+		x, y := elliptic.P256().ScalarBaseMult(big.NewInt(k).Bytes())
+		if p.X.Cmp(x) != 0 || p.Y.Cmp(y) != 0 {
+			t.Fatalf("%d*G = %s, expected (%s, %s)", k, p, x, y)
+		}
+		if !c.IsOnCurve(p) {
+			t.Fatalf("%d*G not on curve", k)
+		}
+	}
+	if p := c.ScalarMult(g, elliptic.P256().Params().N); !p.IsInfinity() {
+		t.Fatalf("n*G = %s, expected infinity", p)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	c, g := p256()
+	g2 := c.Double(g)
+	g3 := c.Add(g2, g)
+	if !g3.Equal(c.ScalarMult(g, big.NewInt(3))) {
+		t.Fatal("2G + G != 3G")
+	}
+	if !c.Add(g, c.Neg(g)).IsInfinity() {
+		t.Fatal("G - G != infinity")
+	}
+	if !c.Add(Infinity(), g).Equal(g) {
+		t.Fatal("inf + G != G")
+	}
+}
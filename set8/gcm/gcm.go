@@ -0,0 +1,166 @@
+package gcm
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	BlockSize = 16
+	NonceSize = 12
+	TagSize   = 16
+)
+
+var errOpen = errors.New("gcm: message authentication failed")
+
+// GCM implements cipher.AEAD with 96-bit nonces.
+type GCM struct {
+	b       cipher.Block
+	h       Elem
+	tab     *table
+	tagSize int
+}
+
+func NewGCM(b cipher.Block) (*GCM, error) {
+	return NewGCMWithTagSize(b, TagSize)
+}
+
+// NewGCMWithTagSize truncates tags to size bytes. Short tags are insecure.
+func NewGCMWithTagSize(b cipher.Block, size int) (*GCM, error) {
+	if b.BlockSize() != BlockSize {
+		return nil, errors.New("gcm: cipher must have 128-bit blocks")
+	}
+	if size < 1 || size > TagSize {
+		return nil, errors.New("gcm: invalid tag size")
+	}
+	h := make([]byte, BlockSize)
+	b.Encrypt(h, h)
+	he := NewElem(h)
+	return &GCM{b, he, newTable(he), size}, nil
+}
+
+func (g *GCM) NonceSize() int {
+	return NonceSize
+}
+
+func (g *GCM) Overhead() int {
+	return g.tagSize
+}
+
+// H returns the authentication key, for tests and attacks.
+func (g *GCM) H() Elem {
+	return g.h
+}
+
+// Blocks returns the blocks authenticated by GHASH: the padded additional
+// data, the padded ciphertext and the lengths block.
+func Blocks(ad, ct []byte) []Elem {
+	var blocks []Elem
+	for _, data := range [][]byte{ad, ct} {
+		for i := 0; i < len(data); i += BlockSize {
+			b := make([]byte, BlockSize)
+			copy(b, data[i:])
+			blocks = append(blocks, NewElem(b))
+		}
+	}
+	l := make([]byte, BlockSize)
+	binary.BigEndian.PutUint64(l, uint64(len(ad))*8)
+	binary.BigEndian.PutUint64(l[8:], uint64(len(ct))*8)
+	return append(blocks, NewElem(l))
+}
+
+// GHASH computes b_1*h^n + b_2*h^(n-1) + ... + b_n*h.
+func GHASH(h Elem, blocks []Elem) Elem {
+	var y Elem
+	for _, b := range blocks {
+		y = y.Add(b).Mul(h)
+	}
+	return y
+}
+
+// ghash is GHASH(H, Blocks(ad, ct)) without allocating the blocks
+func (g *GCM) ghash(ad, ct []byte) Elem {
+	var y Elem
+	b := make([]byte, BlockSize)
+	for _, data := range [][]byte{ad, ct} {
+		for i := 0; i < len(data); i += BlockSize {
+			n := copy(b, data[i:])
+			for j := n; j < BlockSize; j++ {
+				b[j] = 0
+			}
+			y = g.tab.mul(y.Add(NewElem(b)))
+		}
+	}
+	binary.BigEndian.PutUint64(b, uint64(len(ad))*8)
+	binary.BigEndian.PutUint64(b[8:], uint64(len(ct))*8)
+	return g.tab.mul(y.Add(NewElem(b)))
+}
+
+func (g *GCM) counter(nonce []byte) []byte {
+	j0 := make([]byte, BlockSize)
+	copy(j0, nonce)
+	j0[BlockSize-1] = 1
+	return j0
+}
+
+func inc32(ctr []byte) {
+	n := binary.BigEndian.Uint32(ctr[12:])
+	binary.BigEndian.PutUint32(ctr[12:], n+1)
+}
+
+// ctr encrypts src in counter mode starting from the block after j0
+func (g *GCM) ctr(dst, src, j0 []byte) {
+	ctr := make([]byte, BlockSize)
+	copy(ctr, j0)
+	ks := make([]byte, BlockSize)
+	for i := 0; i < len(src); i += BlockSize {
+		inc32(ctr)
+		g.b.Encrypt(ks, ctr)
+		for j := 0; j < BlockSize && i+j < len(src); j++ {
+			dst[i+j] = src[i+j] ^ ks[j]
+		}
+	}
+}
+
+// mask returns the block E(K, J0) added to GHASH to make the tag.
+func (g *GCM) mask(j0 []byte) Elem {
+	s := make([]byte, BlockSize)
+	g.b.Encrypt(s, j0)
+	return NewElem(s)
+}
+
+func (g *GCM) tag(j0, ad, ct []byte) []byte {
+	t := g.ghash(ad, ct).Add(g.mask(j0))
+	return t.Bytes()[:g.tagSize]
+}
+
+func (g *GCM) Seal(dst, nonce, plaintext, ad []byte) []byte {
+	if len(nonce) != NonceSize {
+		panic("gcm: incorrect nonce length")
+	}
+	j0 := g.counter(nonce)
+	ct := make([]byte, len(plaintext))
+	g.ctr(ct, plaintext, j0)
+	dst = append(dst, ct...)
+	return append(dst, g.tag(j0, ad, ct)...)
+}
+
+func (g *GCM) Open(dst, nonce, ciphertext, ad []byte) ([]byte, error) {
+	if len(nonce) != NonceSize {
+		panic("gcm: incorrect nonce length")
+	}
+	if len(ciphertext) < g.tagSize {
+		return nil, errOpen
+	}
+	j0 := g.counter(nonce)
+	ct := ciphertext[:len(ciphertext)-g.tagSize]
+	tag := ciphertext[len(ct):]
+	if subtle.ConstantTimeCompare(g.tag(j0, ad, ct), tag) != 1 {
+		return nil, errOpen
+	}
+	pt := make([]byte, len(ct))
+	g.ctr(pt, ct, j0)
+	return append(dst, pt...), nil
+}
@@ -0,0 +1,78 @@
+package gcm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+)
+
+func random(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestElem(t *testing.T) {
+	b := random(16)
+	if !bytes.Equal(NewElem(b).Bytes(), b) {
+		t.Fatal("block conversion is not reversible")
+	}
+	// the block with only the first bit set is 1
+	one := make([]byte, 16)
+	one[0] = 0x80
+	if NewElem(one) != One {
+		t.Fatalf("unexpected one: %s", NewElem(one))
+	}
+	for i := 0; i < 10; i++ {
+		a, c := RandomElem(), RandomElem()
+		if a.Mul(c) != c.Mul(a) {
+			t.Fatal("multiplication is not commutative")
+		}
+		if a.Mul(a.Inv()) != One {
+			t.Fatalf("%s times its inverse is not one", a)
+		}
+		if a.Sqrt().Square() != a {
+			t.Fatalf("wrong square root of %s", a)
+		}
+	}
+}
+
+func TestGCM(t *testing.T) {
+	for _, size := range []int{0, 1, 15, 16, 17, 64, 100} {
+		key := random(16)
+		nonce := random(NonceSize)
+		pt := random(size)
+		ad := random(size / 2)
+		b, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		std, err := cipher.NewGCM(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := NewGCM(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct := g.Seal(nil, nonce, pt, ad)
+		if exp := std.Seal(nil, nonce, pt, ad); !bytes.Equal(ct, exp) {
+			t.Fatalf("size %d: expected %x, got %x", size, exp, ct)
+		}
+		dec, err := g.Open(nil, nonce, ct, ad)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(dec, pt) {
+			t.Fatalf("size %d: decrypted %x, expected %x", size, dec, pt)
+		}
+		ct[0] ^= 1
+		if _, err := g.Open(nil, nonce, ct, ad); err == nil {
+			t.Fatalf("size %d: tampered ciphertext accepted", size)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Package gcm implements AES-GCM from its parts, GF(2^128) arithmetic and
+// polynomials over it, as needed to attack GCM.
+package gcm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+// Elem is an element of GF(2^128) modulo x^128 + x^7 + x^2 + x + 1. Bit i
+// of Lo (i < 64) or Hi (i >= 64) is the coefficient of x^i.
+type Elem struct {
+	Lo, Hi uint64
+}
+
+var (
+	Zero = Elem{}
+	One  = Elem{Lo: 1}
+)
+
+// NewElem converts a 16 bytes GCM block: the most significant bit of the
+// first byte is the coefficient of x^0.
+func NewElem(b []byte) Elem {
+	return Elem{
+		Lo: bits.Reverse64(binary.BigEndian.Uint64(b)),
+		Hi: bits.Reverse64(binary.BigEndian.Uint64(b[8:])),
+	}
+}
+
+// Bytes returns the element as a GCM block.
+func (e Elem) Bytes() []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, bits.Reverse64(e.Lo))
+	binary.BigEndian.PutUint64(b[8:], bits.Reverse64(e.Hi))
+	return b
+}
+
+func (e Elem) String() string {
+	return fmt.Sprintf("%x", e.Bytes())
+}
+
+func (e Elem) IsZero() bool {
+	return e.Lo == 0 && e.Hi == 0
+}
+
+// Bit returns the coefficient of x^i.
+func (e Elem) Bit(i uint) uint64 {
+	if i < 64 {
+		return (e.Lo >> i) & 1
+	}
+	return (e.Hi >> (i - 64)) & 1
+}
+
+func (e Elem) Add(f Elem) Elem {
+	return Elem{e.Lo ^ f.Lo, e.Hi ^ f.Hi}
+}
+
+// mulX multiplies by x and reduces
+func (e Elem) mulX() Elem {
+	carry := e.Hi >> 63
+	e.Hi = e.Hi<<1 | e.Lo>>63
+	e.Lo <<= 1
+	if carry != 0 {
+		e.Lo ^= 0x87
+	}
+	return e
+}
+
+func (e Elem) Mul(f Elem) Elem {
+	var r Elem
+	for i := uint(0); i < 128; i++ {
+		if e.Bit(i) != 0 {
+			r = r.Add(f)
+		}
+		f = f.mulX()
+	}
+	return r
+}
+
+func (e Elem) Square() Elem {
+	return e.Mul(e)
+}
+
+// Pow2k computes e^(2^k), which is linear over GF(2).
+func (e Elem) Pow2k(k int) Elem {
+	for i := 0; i < k; i++ {
+		e = e.Square()
+	}
+	return e
+}
+
+// Inv computes e^(2^128 - 2). The inverse of zero is zero.
+func (e Elem) Inv() Elem {
+	// 2^128 - 2 = 2 + 4 + ... + 2^127
+	r := One
+	s := e
+	for i := 0; i < 127; i++ {
+		s = s.Square()
+		r = r.Mul(s)
+	}
+	return r
+}
+
+// Sqrt computes e^(2^127), the unique square root.
+func (e Elem) Sqrt() Elem {
+	return e.Pow2k(127)
+}
+
+// RandomElem returns a uniformly random element.
+func RandomElem() Elem {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return NewElem(b)
+}
+
+// table holds the products of a fixed element with every byte value at
+// every byte position, so that a multiplication is 16 lookups.
+type table [16][256]Elem
+
+func newTable(h Elem) *table {
+	t := &table{}
+	for j := range t {
+		for b := uint(0); b < 8; b++ {
+			t[j][1<<b] = h
+			h = h.mulX()
+		}
+		for v := 1; v < 256; v++ {
+			low := v & -v
+			t[j][v] = t[j][low].Add(t[j][v^low])
+		}
+	}
+	return t
+}
+
+func (t *table) mul(e Elem) Elem {
+	var r Elem
+	for j := uint(0); j < 8; j++ {
+		r = r.Add(t[j][(e.Lo>>(8*j))&0xff])
+		r = r.Add(t[j+8][(e.Hi>>(8*j))&0xff])
+	}
+	return r
+}
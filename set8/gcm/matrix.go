@@ -0,0 +1,212 @@
+package gcm
+
+import "math/bits"
+
+// Matrix is a dense matrix over GF(2), each row is a bitset.
+type Matrix struct {
+	Rows, Cols int
+	data       [][]uint64
+}
+
+func NewMatrix(rows, cols int) *Matrix {
+	m := &Matrix{rows, cols, make([][]uint64, rows)}
+	for i := range m.data {
+		m.data[i] = make([]uint64, (cols+63)/64)
+	}
+	return m
+}
+
+// Identity returns the n by n identity matrix.
+func Identity(n int) *Matrix {
+	m := NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		m.Set(i, i, 1)
+	}
+	return m
+}
+
+func (m *Matrix) Get(i, j int) uint64 {
+	return (m.data[i][j/64] >> uint(j%64)) & 1
+}
+
+func (m *Matrix) Set(i, j int, v uint64) {
+	w := &m.data[i][j/64]
+	*w = *w&^(1<<uint(j%64)) | (v&1)<<uint(j%64)
+}
+
+// Row returns the bitset of row i, it is not a copy.
+func (m *Matrix) Row(i int) []uint64 {
+	return m.data[i]
+}
+
+func (m *Matrix) Add(n *Matrix) *Matrix {
+	r := NewMatrix(m.Rows, m.Cols)
+	for i := range r.data {
+		for w := range r.data[i] {
+			r.data[i][w] = m.data[i][w] ^ n.data[i][w]
+		}
+	}
+	return r
+}
+
+func (m *Matrix) Mul(n *Matrix) *Matrix {
+	if m.Cols != n.Rows {
+		panic("gcm: matrix size mismatch")
+	}
+	r := NewMatrix(m.Rows, n.Cols)
+	for i := range r.data {
+		for j := 0; j < m.Cols; j++ {
+			if m.Get(i, j) == 0 {
+				continue
+			}
+			for w, x := range n.data[j] {
+				r.data[i][w] ^= x
+			}
+		}
+	}
+	return r
+}
+
+// MulElem computes the product with the column vector of the coefficients
+// of e, the matrix must be 128 by 128.
+func (m *Matrix) MulElem(e Elem) Elem {
+	var r Elem
+	for i := 0; i < 128; i++ {
+		row := m.data[i]
+		v := uint64(bits.OnesCount64(row[0]&e.Lo)+bits.OnesCount64(row[1]&e.Hi)) & 1
+		if i < 64 {
+			r.Lo |= v << uint(i)
+		} else {
+			r.Hi |= v << uint(i-64)
+		}
+	}
+	return r
+}
+
+// Column returns column j of a 128 rows matrix as an element.
+func (m *Matrix) Column(j int) Elem {
+	var e Elem
+	for i := 0; i < 128; i++ {
+		if i < 64 {
+			e.Lo |= m.Get(i, j) << uint(i)
+		} else {
+			e.Hi |= m.Get(i, j) << uint(i-64)
+		}
+	}
+	return e
+}
+
+func (m *Matrix) setColumn(j int, e Elem) {
+	for i := 0; i < 128; i++ {
+		m.Set(i, j, e.Bit(uint(i)))
+	}
+}
+
+// MulMatrix returns the matrix of the multiplication by c: column j is
+// c*x^j.
+func MulMatrix(c Elem) *Matrix {
+	m := NewMatrix(128, 128)
+	for j := 0; j < 128; j++ {
+		m.setColumn(j, c)
+		c = c.mulX()
+	}
+	return m
+}
+
+// SquareMatrix returns the matrix of squaring, which is linear in
+// characteristic 2: column j is x^(2j).
+func SquareMatrix() *Matrix {
+	m := NewMatrix(128, 128)
+	x := One
+	for j := 0; j < 128; j++ {
+		m.setColumn(j, x.Square())
+		x = x.mulX()
+	}
+	return m
+}
+
+func bit(row []uint64, j int) uint64 {
+	return (row[j/64] >> uint(j%64)) & 1
+}
+
+// echelon brings a copy of the rows to reduced row echelon form considering
+// the first cols columns, it returns the rows and the pivot columns.
+func echelon(data [][]uint64, cols int) ([][]uint64, []int) {
+	rows := make([][]uint64, len(data))
+	for i := range rows {
+		rows[i] = make([]uint64, len(data[i]))
+		copy(rows[i], data[i])
+	}
+	var pivots []int
+	r := 0
+	for j := 0; j < cols && r < len(rows); j++ {
+		p := -1
+		for i := r; i < len(rows); i++ {
+			if bit(rows[i], j) != 0 {
+				p = i
+				break
+			}
+		}
+		if p < 0 {
+			continue
+		}
+		rows[r], rows[p] = rows[p], rows[r]
+		for i := range rows {
+			if i == r || bit(rows[i], j) == 0 {
+				continue
+			}
+			for w, x := range rows[r] {
+				rows[i][w] ^= x
+			}
+		}
+		pivots = append(pivots, j)
+		r++
+	}
+	return rows, pivots
+}
+
+// Kernel returns a basis of the vectors v such that m*v = 0, as bitsets of
+// m.Cols bits.
+func (m *Matrix) Kernel() [][]uint64 {
+	rows, pivots := echelon(m.data, m.Cols)
+	isPivot := make([]bool, m.Cols)
+	for _, j := range pivots {
+		isPivot[j] = true
+	}
+	var basis [][]uint64
+	for f := 0; f < m.Cols; f++ {
+		if isPivot[f] {
+			continue
+		}
+		v := make([]uint64, (m.Cols+63)/64)
+		v[f/64] |= 1 << uint(f%64)
+		for i, j := range pivots {
+			if bit(rows[i], f) != 0 {
+				v[j/64] |= 1 << uint(j%64)
+			}
+		}
+		basis = append(basis, v)
+	}
+	return basis
+}
+
+// Solve returns a vector v such that m*v = b, where b has a bit per row.
+// All solutions are v plus a vector of the kernel.
+func (m *Matrix) Solve(b []uint64) ([]uint64, bool) {
+	aug := NewMatrix(m.Rows, m.Cols+1)
+	for i := range aug.data {
+		copy(aug.data[i], m.data[i])
+		aug.Set(i, m.Cols, bit(b, i))
+	}
+	rows, pivots := echelon(aug.data, aug.Cols)
+	v := make([]uint64, (m.Cols+63)/64)
+	for i, j := range pivots {
+		if j == m.Cols {
+			return nil, false
+		}
+		if bit(rows[i], m.Cols) != 0 {
+			v[j/64] |= 1 << uint(j%64)
+		}
+	}
+	return v, true
+}
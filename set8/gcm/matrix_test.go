@@ -0,0 +1,73 @@
+package gcm
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func TestMulMatrix(t *testing.T) {
+	c, h := RandomElem(), RandomElem()
+	if MulMatrix(c).MulElem(h) != c.Mul(h) {
+		t.Fatal("multiplication matrix is wrong")
+	}
+	if SquareMatrix().MulElem(h) != h.Square() {
+		t.Fatal("squaring matrix is wrong")
+	}
+	// M_c * M_s * h = c * h^2
+	if MulMatrix(c).Mul(SquareMatrix()).MulElem(h) != c.Mul(h.Square()) {
+		t.Fatal("matrix product is wrong")
+	}
+}
+
+func TestKernel(t *testing.T) {
+	m := NewMatrix(100, 150)
+	for i := 0; i < m.Rows; i++ {
+		e := RandomElem()
+		m.Row(i)[0], m.Row(i)[1] = e.Lo, e.Hi
+		m.Row(i)[2] = RandomElem().Lo & (1<<22 - 1)
+	}
+	basis := m.Kernel()
+	if len(basis) < 50 {
+		t.Fatalf("kernel of dimension %d", len(basis))
+	}
+	for _, v := range basis {
+		col := NewMatrix(150, 1)
+		for j := 0; j < 150; j++ {
+			col.Set(j, 0, (v[j/64]>>uint(j%64))&1)
+		}
+		prod := m.Mul(col)
+		for i := 0; i < prod.Rows; i++ {
+			if prod.Get(i, 0) != 0 {
+				t.Fatal("vector not in the kernel")
+			}
+		}
+	}
+}
+
+func TestSolve(t *testing.T) {
+	m := NewMatrix(60, 128)
+	for i := 0; i < m.Rows; i++ {
+		e := RandomElem()
+		m.Row(i)[0], m.Row(i)[1] = e.Lo, e.Hi
+	}
+	x := RandomElem()
+	b := make([]uint64, 1)
+	for i := 0; i < m.Rows; i++ {
+		b[0] |= (uint64(bits.OnesCount64(m.Row(i)[0]&x.Lo)+bits.OnesCount64(m.Row(i)[1]&x.Hi)) & 1) << uint(i)
+	}
+	v, ok := m.Solve(b)
+	if !ok {
+		t.Fatal("no solution found")
+	}
+	for i := 0; i < m.Rows; i++ {
+		if (bits.OnesCount64(m.Row(i)[0]&v[0])+bits.OnesCount64(m.Row(i)[1]&v[1]))&1 != int(b[0]>>uint(i))&1 {
+			t.Fatalf("row %d not satisfied", i)
+		}
+	}
+	// a zero row with a one in b has no solution
+	m.Row(0)[0], m.Row(0)[1] = 0, 0
+	b[0] |= 1
+	if _, ok := m.Solve(b); ok {
+		t.Fatal("inconsistent system solved")
+	}
+}
@@ -0,0 +1,305 @@
+package gcm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+)
+
+// Poly is a polynomial over GF(2^128), p[i] is the coefficient of X^i.
+// Polynomials returned by this package have a non-zero leading coefficient.
+type Poly []Elem
+
+// NewPoly builds a polynomial from coefficients from X^0 up.
+func NewPoly(cs ...Elem) Poly {
+	p := make(Poly, len(cs))
+	copy(p, cs)
+	return p.norm()
+}
+
+func (p Poly) norm() Poly {
+	n := len(p)
+	for n > 0 && p[n-1].IsZero() {
+		n--
+	}
+	return p[:n]
+}
+
+// Degree of the zero polynomial is -1.
+func (p Poly) Degree() int {
+	return len(p.norm()) - 1
+}
+
+func (p Poly) IsZero() bool {
+	return p.Degree() < 0
+}
+
+func (p Poly) IsOne() bool {
+	return p.Degree() == 0 && p[0] == One
+}
+
+func (p Poly) Equal(q Poly) bool {
+	p, q = p.norm(), q.norm()
+	if len(p) != len(q) {
+		return false
+	}
+	for i := range p {
+		if p[i] != q[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p Poly) String() string {
+	if p.IsZero() {
+		return "0"
+	}
+	var b bytes.Buffer
+	for i := len(p) - 1; i >= 0; i-- {
+		if p[i].IsZero() {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(" + ")
+		}
+		fmt.Fprintf(&b, "%s*X^%d", p[i], i)
+	}
+	return b.String()
+}
+
+func (p Poly) Add(q Poly) Poly {
+	if len(p) < len(q) {
+		p, q = q, p
+	}
+	r := make(Poly, len(p))
+	copy(r, p)
+	for i := range q {
+		r[i] = r[i].Add(q[i])
+	}
+	return r.norm()
+}
+
+func (p Poly) Mul(q Poly) Poly {
+	p, q = p.norm(), q.norm()
+	if len(p) == 0 || len(q) == 0 {
+		return nil
+	}
+	r := make(Poly, len(p)+len(q)-1)
+	for i := range p {
+		if p[i].IsZero() {
+			continue
+		}
+		for j := range q {
+			r[i+j] = r[i+j].Add(p[i].Mul(q[j]))
+		}
+	}
+	return r.norm()
+}
+
+// Scale multiplies all coefficients by c.
+func (p Poly) Scale(c Elem) Poly {
+	r := make(Poly, len(p))
+	for i := range p {
+		r[i] = p[i].Mul(c)
+	}
+	return r.norm()
+}
+
+// DivMod returns quotient and remainder of p divided by q.
+func (p Poly) DivMod(q Poly) (Poly, Poly) {
+	q = q.norm()
+	if len(q) == 0 {
+		panic("gcm: division by zero polynomial")
+	}
+	r := make(Poly, len(p))
+	copy(r, p)
+	r = r.norm()
+	if len(r) < len(q) {
+		return nil, r
+	}
+	quo := make(Poly, len(r)-len(q)+1)
+	inv := q[len(q)-1].Inv()
+	for len(r) >= len(q) {
+		shift := len(r) - len(q)
+		c := r[len(r)-1].Mul(inv)
+		quo[shift] = c
+		for i := range q {
+			r[shift+i] = r[shift+i].Add(c.Mul(q[i]))
+		}
+		r = r.norm()
+	}
+	return quo.norm(), r
+}
+
+func (p Poly) Div(q Poly) Poly {
+	quo, _ := p.DivMod(q)
+	return quo
+}
+
+func (p Poly) Mod(q Poly) Poly {
+	_, r := p.DivMod(q)
+	return r
+}
+
+// Monic divides by the leading coefficient.
+func (p Poly) Monic() Poly {
+	p = p.norm()
+	if len(p) == 0 {
+		return p
+	}
+	return p.Scale(p[len(p)-1].Inv())
+}
+
+// GCD returns the monic greatest common divisor.
+func GCD(p, q Poly) Poly {
+	p, q = p.norm(), q.norm()
+	for !q.IsZero() {
+		p, q = q, p.Mod(q)
+	}
+	return p.Monic()
+}
+
+// Deriv is the formal derivative: in characteristic 2 the terms of even
+// degree vanish.
+func (p Poly) Deriv() Poly {
+	if len(p) < 2 {
+		return nil
+	}
+	r := make(Poly, len(p)-1)
+	for i := 1; i < len(p); i += 2 {
+		r[i-1] = p[i]
+	}
+	return r.norm()
+}
+
+// sqrt of a polynomial with only terms of even degree
+func (p Poly) sqrt() Poly {
+	r := make(Poly, (len(p)+1)/2)
+	for i := range r {
+		r[i] = p[2*i].Sqrt()
+	}
+	return r.norm()
+}
+
+// PowMod computes p^e mod m.
+func (p Poly) PowMod(e *big.Int, m Poly) Poly {
+	r := NewPoly(One).Mod(m)
+	b := p.Mod(m)
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		r = r.Mul(r).Mod(m)
+		if e.Bit(i) != 0 {
+			r = r.Mul(b).Mod(m)
+		}
+	}
+	return r
+}
+
+// Factor is a polynomial and, depending on the factorization, its
+// multiplicity or the degree of its irreducible factors.
+type Factor struct {
+	P Poly
+	N int
+}
+
+// SquareFree returns the square-free factorization of a monic polynomial:
+// pairwise coprime square-free factors with their multiplicity.
+func SquareFree(f Poly) []Factor {
+	var fs []Factor
+	c := GCD(f, f.Deriv())
+	w := f.Div(c)
+	for i := 1; !w.IsOne(); i++ {
+		y := GCD(w, c)
+		if fac := w.Div(y); !fac.IsOne() {
+			fs = append(fs, Factor{fac, i})
+		}
+		w = y
+		c = c.Div(y)
+	}
+	if !c.IsOne() {
+		for _, fac := range SquareFree(c.sqrt()) {
+			fs = append(fs, Factor{fac.P, fac.N * 2})
+		}
+	}
+	return fs
+}
+
+// DistinctDegree splits a monic square-free polynomial into products of
+// irreducible factors of the same degree.
+func DistinctDegree(f Poly) []Factor {
+	var fs []Factor
+	x := NewPoly(Zero, One)
+	h := x
+	for i := 1; f.Degree() >= 2*i; i++ {
+		// h = X^(q^i) mod f
+		for j := 0; j < 128; j++ {
+			h = h.Mul(h).Mod(f)
+		}
+		g := GCD(f, h.Add(x))
+		if !g.IsOne() {
+			fs = append(fs, Factor{g, i})
+			f = f.Div(g)
+			h = h.Mod(f)
+		}
+	}
+	if f.Degree() > 0 {
+		fs = append(fs, Factor{f, f.Degree()})
+	}
+	return fs
+}
+
+func randomPoly(deg int) Poly {
+	p := make(Poly, deg+1)
+	for i := range p {
+		p[i] = RandomElem()
+	}
+	return p.norm()
+}
+
+// EqualDegree splits a monic square-free polynomial whose irreducible
+// factors all have degree d (Cantor-Zassenhaus). As q^d - 1 is divisible
+// by 3, h^((q^d - 1)/3) is a cube root of unity modulo each factor and it
+// is one for about a third of them.
+func EqualDegree(f Poly, d int) []Poly {
+	n := f.Degree() / d
+	e := (&big.Int{}).Lsh(big.NewInt(1), uint(128*d))
+	e.Sub(e, big.NewInt(1))
+	e.Div(e, big.NewInt(3))
+	fs := []Poly{f}
+	for len(fs) < n {
+		h := randomPoly(f.Degree() - 1)
+		g := h.PowMod(e, f).Add(NewPoly(One))
+		var next []Poly
+		for _, u := range fs {
+			if u.Degree() == d {
+				next = append(next, u)
+				continue
+			}
+			c := GCD(g, u)
+			if c.IsOne() || c.Degree() == u.Degree() {
+				next = append(next, u)
+				continue
+			}
+			next = append(next, c, u.Div(c))
+		}
+		fs = next
+	}
+	return fs
+}
+
+// Roots returns the distinct roots of p.
+func (p Poly) Roots() []Elem {
+	var roots []Elem
+	for _, sf := range SquareFree(p.Monic()) {
+		for _, df := range DistinctDegree(sf.P) {
+			if df.N != 1 {
+				continue
+			}
+			for _, lin := range EqualDegree(df.P, 1) {
+				// X + r
+				roots = append(roots, lin[0])
+			}
+		}
+	}
+	return roots
+}
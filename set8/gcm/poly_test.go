@@ -0,0 +1,51 @@
+package gcm
+
+import "testing"
+
+func TestDivMod(t *testing.T) {
+	p := randomPoly(7)
+	q := randomPoly(3)
+	quo, rem := p.DivMod(q)
+	if rem.Degree() >= q.Degree() {
+		t.Fatalf("remainder of degree %d", rem.Degree())
+	}
+	if !quo.Mul(q).Add(rem).Equal(p) {
+		t.Fatal("p != quo*q + rem")
+	}
+}
+
+func TestRoots(t *testing.T) {
+	var roots []Elem
+	f := NewPoly(One)
+	for i := 0; i < 4; i++ {
+		r := RandomElem()
+		roots = append(roots, r)
+		f = f.Mul(NewPoly(r, One))
+	}
+	// a repeated root and an irreducible quadratic factor
+	f = f.Mul(NewPoly(roots[0], One))
+	for {
+		q := randomPoly(2).Monic()
+		if len(DistinctDegree(q)) == 1 && DistinctDegree(q)[0].N == 2 {
+			f = f.Mul(q)
+			break
+		}
+	}
+	f = f.Scale(RandomElem())
+
+	found := f.Roots()
+	if len(found) != len(roots) {
+		t.Fatalf("expected %d roots, found %d", len(roots), len(found))
+	}
+	for _, r := range roots {
+		ok := false
+		for _, x := range found {
+			if x == r {
+				ok = true
+			}
+		}
+		if !ok {
+			t.Fatalf("root %s not found", r)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package gcm
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	mrand "math/rand"
+)
+
+// Oracle seals messages with truncated tags under a random key and tells
+// if a ciphertext is authentic, counting the queries.
+type Oracle struct {
+	g       *GCM
+	Queries int
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// NewOracle uses a random AES-128 key and tags of tagSize bytes.
+func NewOracle(tagSize int) (*Oracle, error) {
+	b, err := aes.NewCipher(randomBytes(16))
+	if err != nil {
+		return nil, err
+	}
+	g, err := NewGCMWithTagSize(b, tagSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Oracle{g: g}, nil
+}
+
+// H returns the authentication key, to check the attacks.
+func (o *Oracle) H() Elem {
+	return o.g.H()
+}
+
+// Seal encrypts pt with a random nonce.
+func (o *Oracle) Seal(pt []byte) (nonce, ct, tag []byte) {
+	nonce = randomBytes(NonceSize)
+	out := o.g.Seal(nil, nonce, pt, nil)
+	n := len(out) - o.g.Overhead()
+	return nonce, out[:n], out[n:]
+}
+
+// Check tells if tag authenticates ct.
+func (o *Oracle) Check(nonce, ct, tag []byte) bool {
+	o.Queries++
+	_, err := o.g.Open(nil, nonce, append(ct[:len(ct):len(ct)], tag...), nil)
+	return err == nil
+}
+
+// Attack recovers H from forgeries against truncated tags, changing only
+// the ciphertext blocks d_i that are multiplied by H^(2^i), i = 1..n. As
+// squaring is linear, the error in the tag is Ad*H with
+// Ad = M(delta) + sum M(d_i)*Ms^i, where delta is a fixed change of the
+// lengths block, usually zero. Each forgery that zeroes the first rows of
+// Ad and is accepted gives linear equations on the bits of H, the known
+// rows K. Writing H = X*h' with X a basis of the kernel of K, every round
+// can zero more rows and needs fewer queries.
+type Attack struct {
+	n       int
+	tagBits int
+	delta   Elem
+	squares []*Matrix // Ms^i for i = 1..n
+	mulx    []*Matrix // M(x^b) for b = 0..127
+	known   []Elem
+	x       *Matrix
+	// MaxAttempts is the number of forgeries a round tries before failing.
+	MaxAttempts int
+}
+
+// ErrAttempts is returned when a round reaches MaxAttempts.
+var ErrAttempts = errors.New("gcm: too many forgery attempts")
+
+// NewAttack prepares the attack on a ciphertext of 2^n blocks at least,
+// with tags of tagBits bits and the change delta to the lengths block.
+func NewAttack(n, tagBits int, delta Elem) *Attack {
+	a := &Attack{
+		n:           n,
+		tagBits:     tagBits,
+		delta:       delta,
+		x:           Identity(128),
+		MaxAttempts: 1 << 24,
+	}
+	ms := SquareMatrix()
+	sq := Identity(128)
+	for i := 0; i < n; i++ {
+		sq = ms.Mul(sq)
+		a.squares = append(a.squares, sq)
+	}
+	for b := uint(0); b < 128; b++ {
+		var c Elem
+		if b < 64 {
+			c.Lo = 1 << b
+		} else {
+			c.Hi = 1 << (b - 64)
+		}
+		a.mulx = append(a.mulx, MulMatrix(c))
+	}
+	return a
+}
+
+// Unknown is the number of bits of H still unknown.
+func (a *Attack) Unknown() int {
+	return a.x.Cols
+}
+
+// H returns a candidate for H, the only one when Unknown is one.
+func (a *Attack) H() Elem {
+	return a.x.Column(0)
+}
+
+// ad builds Ad for the errors ds[i] added to d_(i+1)
+func (a *Attack) ad(ds []Elem) *Matrix {
+	m := MulMatrix(a.delta)
+	for i, d := range ds {
+		m = m.Add(MulMatrix(d).Mul(a.squares[i]))
+	}
+	return m
+}
+
+// zeroRows is the number of rows of Ad*X to force to zero: the solutions
+// of the dependency matrix must be enough to try many forgeries.
+func (a *Attack) zeroRows() int {
+	for k := a.tagBits - 1; k > 0; k-- {
+		if a.n*128-k*a.x.Cols >= a.tagBits-k+8 {
+			return k
+		}
+	}
+	return 0
+}
+
+// dependency returns T and c such that T*d + c is the first k rows of
+// Ad*X, flattened, where d are the n*128 bits of the errors.
+func (a *Attack) dependency(k int) (*Matrix, []uint64) {
+	cols := a.x.Cols
+	t := NewMatrix(k*cols, a.n*128)
+	c := make([]uint64, (k*cols+63)/64)
+	y := MulMatrix(a.delta).Mul(a.x)
+	for r := 0; r < k; r++ {
+		for j := 0; j < cols; j++ {
+			i := r*cols + j
+			c[i/64] |= y.Get(r, j) << uint(i%64)
+		}
+	}
+	for i := 0; i < a.n; i++ {
+		p := a.squares[i].Mul(a.x)
+		for b := 0; b < 128; b++ {
+			y := a.mulx[b].Mul(p)
+			for r := 0; r < k; r++ {
+				for j := 0; j < cols; j++ {
+					t.Set(r*cols+j, i*128+b, y.Get(r, j))
+				}
+			}
+		}
+	}
+	return t, c
+}
+
+// forgery picks a random solution of T*d = c, or nil if it would leave the
+// ciphertext unchanged.
+func (a *Attack) forgery(sol []uint64, basis [][]uint64, rnd *mrand.Rand) []Elem {
+	v := make([]uint64, a.n*2)
+	copy(v, sol)
+	for _, b := range basis {
+		if rnd.Intn(2) == 0 {
+			continue
+		}
+		for w := range v {
+			v[w] ^= b[w]
+		}
+	}
+	ds := make([]Elem, a.n)
+	zero := a.delta.IsZero()
+	for i := range ds {
+		ds[i] = Elem{Lo: v[2*i], Hi: v[2*i+1]}
+		if !ds[i].IsZero() {
+			zero = false
+		}
+	}
+	if zero {
+		return nil
+	}
+	return ds
+}
+
+// update adds the rows of Ad that the accepted forgery proved to be zero
+// when multiplied by H, and recomputes X.
+func (a *Attack) update(ds []Elem) {
+	m := a.ad(ds)
+	for r := 0; r < a.tagBits; r++ {
+		row := m.Row(r)
+		a.known = append(a.known, Elem{Lo: row[0], Hi: row[1]})
+	}
+	k := NewMatrix(len(a.known), 128)
+	for i, e := range a.known {
+		k.Row(i)[0], k.Row(i)[1] = e.Lo, e.Hi
+	}
+	basis := k.Kernel()
+	x := NewMatrix(128, len(basis))
+	for j, v := range basis {
+		for i := 0; i < 128; i++ {
+			x.Set(i, j, (v[i/64]>>uint(i%64))&1)
+		}
+	}
+	a.x = x
+}
+
+// Round tries forgeries until check accepts one and learns bits of H from
+// it. It returns the number of rows of Ad that the forgeries zeroed, and
+// ErrAttempts if none of MaxAttempts forgeries is accepted.
+func (a *Attack) Round(check func(ds []Elem) bool, rnd *mrand.Rand) (int, error) {
+	k := a.zeroRows()
+	t, c := a.dependency(k)
+	sol, ok := t.Solve(c)
+	if !ok {
+		return k, fmt.Errorf("gcm: cannot zero %d rows", k)
+	}
+	basis := t.Kernel()
+	for i := 0; i < a.MaxAttempts; i++ {
+		ds := a.forgery(sol, basis, rnd)
+		if ds == nil || !check(ds) {
+			continue
+		}
+		a.update(ds)
+		if a.x.Cols == 0 {
+			return k, errors.New("gcm: no solution for H")
+		}
+		return k, nil
+	}
+	return k, ErrAttempts
+}
+
+// Flip adds the errors to the blocks multiplied by H^(2^i): with b blocks
+// of ciphertext and the lengths block, block j is multiplied by H^(b+1-j).
+func Flip(ct []byte, ds []Elem) {
+	blocks := len(ct) / BlockSize
+	for i, d := range ds {
+		j := blocks + 1 - (1 << uint(i+1))
+		block := ct[j*BlockSize : (j+1)*BlockSize]
+		for k, c := range d.Bytes() {
+			block[k] ^= c
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package gcm
+
+import (
+	mrand "math/rand"
+	"testing"
+)
+
+func recoverH(t *testing.T, o *Oracle, n, tagSize int, delta Elem, nonce, ct, tag []byte) Elem {
+	rnd := mrand.New(mrand.NewSource(1))
+	a := NewAttack(n, tagSize*8, delta)
+	for a.Unknown() > 1 {
+		if _, err := a.Round(func(ds []Elem) bool {
+			Flip(ct, ds)
+			defer Flip(ct, ds)
+			return o.Check(nonce, ct, tag)
+		}, rnd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return a.H()
+}
+
+func TestTruncatedAttack(t *testing.T) {
+	o, err := NewOracle(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, ct, tag := o.Seal(make([]byte, 32*BlockSize))
+	if h := recoverH(t, o, 5, 2, Elem{}, nonce, ct, tag); h != o.H() {
+		t.Fatalf("recovered %s, expected %s", h, o.H())
+	}
+}
+
+func TestLengthAttack(t *testing.T) {
+	o, err := NewOracle(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, ct, tag := o.Seal(make([]byte, 32*BlockSize-5))
+	ext := make([]byte, 32*BlockSize)
+	copy(ext, ct)
+	delta := Blocks(nil, ct)[32].Add(Blocks(nil, ext)[32])
+	if h := recoverH(t, o, 5, 2, delta, nonce, ext, tag); h != o.H() {
+		t.Fatalf("recovered %s, expected %s", h, o.H())
+	}
+}
+
+func TestOracle(t *testing.T) {
+	o, err := NewOracle(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, ct, tag := o.Seal([]byte("attack at dawn"))
+	if !o.Check(nonce, ct, tag) {
+		t.Fatal("sealed message rejected")
+	}
+	ct[0] ^= 1
+	if o.Check(nonce, ct, tag) {
+		t.Fatal("changed message accepted")
+	}
+	if o.Queries != 2 {
+		t.Fatalf("counted %d queries", o.Queries)
+	}
+	if _, err := NewOracle(17); err == nil {
+		t.Fatal("tag of 17 bytes accepted")
+	}
+}
+
+func TestRoundAttempts(t *testing.T) {
+	a := NewAttack(5, 16, Elem{})
+	a.MaxAttempts = 100
+	calls := 0
+	_, err := a.Round(func(ds []Elem) bool {
+		calls++
+		return false
+	}, mrand.New(mrand.NewSource(1)))
+	if err != ErrAttempts {
+		t.Fatalf("expected ErrAttempts, got %v", err)
+	}
+	if calls > a.MaxAttempts {
+		t.Fatalf("%d forgeries checked, limit is %d", calls, a.MaxAttempts)
+	}
+}
@@ -0,0 +1,182 @@
+// Package lattice implements lattice basis reduction over the rationals.
+package lattice
+
+import "math/big"
+
+var half = big.NewRat(1, 2)
+
+// Vector is a row of a lattice basis.
+type Vector []*big.Rat
+
+// NewVector converts integers to a vector.
+func NewVector(xs ...*big.Int) Vector {
+	v := make(Vector, len(xs))
+	for i, x := range xs {
+		v[i] = (&big.Rat{}).SetInt(x)
+	}
+	return v
+}
+
+func (v Vector) Dot(w Vector) *big.Rat {
+	r, t := &big.Rat{}, &big.Rat{}
+	for i := range v {
+		r.Add(r, t.Mul(v[i], w[i]))
+	}
+	return r
+}
+
+// sub computes v - c*w in place
+func (v Vector) sub(w Vector, c *big.Rat) {
+	t := &big.Rat{}
+	for i := range v {
+		v[i].Sub(v[i], t.Mul(w[i], c))
+	}
+}
+
+func (v Vector) Copy() Vector {
+	w := make(Vector, len(v))
+	for i := range v {
+		w[i] = (&big.Rat{}).Set(v[i])
+	}
+	return w
+}
+
+// GramSchmidt returns the orthogonalized basis, not normalized.
+func GramSchmidt(b []Vector) []Vector {
+	q := make([]Vector, len(b))
+	for i := range b {
+		q[i] = b[i].Copy()
+		for j := 0; j < i; j++ {
+			mu := b[i].Dot(q[j])
+			mu.Quo(mu, q[j].Dot(q[j]))
+			q[i].sub(q[j], mu)
+		}
+	}
+	return q
+}
+
+// gs caches the Gram-Schmidt coefficients mu[i][j] and the squared norms of
+// the orthogonal vectors, so that they are updated instead of recomputed
+// after each step of the reduction.
+type gs struct {
+	b    []Vector
+	mu   [][]*big.Rat
+	norm []*big.Rat
+}
+
+func newGS(b []Vector) *gs {
+	g := &gs{
+		b:    b,
+		mu:   make([][]*big.Rat, len(b)),
+		norm: make([]*big.Rat, len(b)),
+	}
+	q := GramSchmidt(b)
+	for i := range b {
+		g.norm[i] = q[i].Dot(q[i])
+		g.mu[i] = make([]*big.Rat, len(b))
+		for j := 0; j < i; j++ {
+			g.mu[i][j] = b[i].Dot(q[j])
+			g.mu[i][j].Quo(g.mu[i][j], g.norm[j])
+		}
+	}
+	return g
+}
+
+func round(x *big.Rat) *big.Rat {
+	t := (&big.Rat{}).Add(x, half)
+	n := (&big.Int{}).Div(t.Num(), t.Denom())
+	return (&big.Rat{}).SetInt(n)
+}
+
+// reduce makes |mu[k][l]| <= 1/2 subtracting a multiple of b[l] from b[k]
+func (g *gs) reduce(k, l int) {
+	if (&big.Rat{}).Abs(g.mu[k][l]).Cmp(half) <= 0 {
+		return
+	}
+	r := round(g.mu[k][l])
+	g.b[k].sub(g.b[l], r)
+	t := &big.Rat{}
+	for j := 0; j < l; j++ {
+		g.mu[k][j].Sub(g.mu[k][j], t.Mul(r, g.mu[l][j]))
+	}
+	g.mu[k][l].Sub(g.mu[k][l], r)
+}
+
+// swap exchanges b[k] and b[k-1] and updates the coefficients
+func (g *gs) swap(k int) {
+	m := g.mu[k][k-1]
+	// B = norm[k] + m^2 norm[k-1]
+	B := (&big.Rat{}).Mul(m, m)
+	B.Mul(B, g.norm[k-1])
+	B.Add(B, g.norm[k])
+	nm := (&big.Rat{}).Mul(m, g.norm[k-1])
+	nm.Quo(nm, B)
+	nk := (&big.Rat{}).Mul(g.norm[k-1], g.norm[k])
+	nk.Quo(nk, B)
+	g.mu[k][k-1] = nm
+	g.norm[k] = nk
+	g.norm[k-1] = B
+
+	g.b[k], g.b[k-1] = g.b[k-1], g.b[k]
+	for j := 0; j < k-1; j++ {
+		g.mu[k][j], g.mu[k-1][j] = g.mu[k-1][j], g.mu[k][j]
+	}
+	t := &big.Rat{}
+	for i := k + 1; i < len(g.b); i++ {
+		tt := g.mu[i][k]
+		// mu[i][k] = mu[i][k-1] - m*t
+		nik := (&big.Rat{}).Sub(g.mu[i][k-1], t.Mul(m, tt))
+		// mu[i][k-1] = t + mu[k][k-1]*mu[i][k]
+		nik1 := (&big.Rat{}).Add(tt, t.Mul(nm, nik))
+		g.mu[i][k], g.mu[i][k-1] = nik, nik1
+	}
+}
+
+// lovasz checks norm[k] >= (delta - mu[k][k-1]^2) * norm[k-1]
+func (g *gs) lovasz(k int, delta *big.Rat) bool {
+	t := (&big.Rat{}).Mul(g.mu[k][k-1], g.mu[k][k-1])
+	t.Sub(delta, t)
+	t.Mul(t, g.norm[k-1])
+	return g.norm[k].Cmp(t) >= 0
+}
+
+// LLL reduces the basis of linearly independent vectors in place, delta is
+// in (1/4, 1]: 3/4 is the classic choice, 99/100 gives shorter vectors.
+func LLL(b []Vector, delta *big.Rat) []Vector {
+	if len(b) < 2 {
+		return b
+	}
+	g := newGS(b)
+	k := 1
+	for k < len(b) {
+		g.reduce(k, k-1)
+		if g.lovasz(k, delta) {
+			for l := k - 2; l >= 0; l-- {
+				g.reduce(k, l)
+			}
+			k++
+			continue
+		}
+		g.swap(k)
+		if k > 1 {
+			k--
+		}
+	}
+	return b
+}
+
+// Closest returns a vector of the lattice with LLL reduced basis b close to
+// w, with Babai's nearest plane algorithm: w is reduced by each vector in
+// turn, from the last, along its orthogonal direction.
+func Closest(b []Vector, w Vector) Vector {
+	q := GramSchmidt(b)
+	r := w.Copy()
+	for i := len(b) - 1; i >= 0; i-- {
+		c := r.Dot(q[i])
+		c.Quo(c, q[i].Dot(q[i]))
+		r.sub(b[i], round(c))
+	}
+	v := w.Copy()
+	v.sub(r, big.NewRat(1, 1))
+	return v
+}
@@ -0,0 +1,106 @@
+package lattice
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func ints(xs ...int64) Vector {
+	v := make(Vector, len(xs))
+	for i, x := range xs {
+		v[i] = big.NewRat(x, 1)
+	}
+	return v
+}
+
+func equal(v, w Vector) bool {
+	for i := range v {
+		if v[i].Cmp(w[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkReduced verifies the size and Lovasz conditions from scratch
+func checkReduced(t *testing.T, b []Vector, delta *big.Rat) {
+	q := GramSchmidt(b)
+	for i := 1; i < len(b); i++ {
+		for j := 0; j < i; j++ {
+			mu := b[i].Dot(q[j])
+			mu.Quo(mu, q[j].Dot(q[j]))
+			if mu.Abs(mu).Cmp(half) > 0 {
+				t.Fatalf("|mu[%d][%d]| > 1/2", i, j)
+			}
+		}
+		mu := b[i].Dot(q[i-1])
+		mu.Quo(mu, q[i-1].Dot(q[i-1]))
+		r := (&big.Rat{}).Mul(mu, mu)
+		r.Sub(delta, r)
+		r.Mul(r, q[i-1].Dot(q[i-1]))
+		if q[i].Dot(q[i]).Cmp(r) < 0 {
+			t.Fatalf("Lovasz condition fails at %d", i)
+		}
+	}
+}
+
+func TestLLL(t *testing.T) {
+	// example from Wikipedia
+	b := []Vector{ints(1, 1, 1), ints(-1, 0, 2), ints(3, 5, 6)}
+	delta := big.NewRat(3, 4)
+	LLL(b, delta)
+	expected := []Vector{ints(0, 1, 0), ints(1, 0, 1), ints(-1, 0, 2)}
+	for i := range b {
+		if !equal(b[i], expected[i]) {
+			t.Fatalf("b[%d] = %v, expected %v", i, b[i], expected[i])
+		}
+	}
+}
+
+func TestLLLRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	delta := big.NewRat(99, 100)
+	for n := 2; n < 12; n++ {
+		b := make([]Vector, n)
+		for i := range b {
+			b[i] = make(Vector, n)
+			for j := range b[i] {
+				b[i][j] = big.NewRat(rnd.Int63n(1<<20)-1<<19, 1)
+			}
+		}
+		checkReduced(t, LLL(b, delta), delta)
+	}
+}
+
+func TestClosest(t *testing.T) {
+	b := []Vector{ints(1, 1, 1), ints(-1, 0, 2), ints(3, 5, 6)}
+	LLL(b, big.NewRat(3, 4))
+	// (1, 2, 1) is in the lattice, the closest vector to a point near it
+	w := Vector{big.NewRat(11, 10), big.NewRat(19, 10), big.NewRat(11, 10)}
+	if v := Closest(b, w); !equal(v, ints(1, 2, 1)) {
+		t.Fatalf("closest vector %v, expected (1, 2, 1)", v)
+	}
+	// a point of the lattice is its own closest vector
+	rnd := rand.New(rand.NewSource(42))
+	for n := 2; n < 8; n++ {
+		b := make([]Vector, n)
+		for i := range b {
+			b[i] = make(Vector, n)
+			for j := range b[i] {
+				b[i][j] = big.NewRat(rnd.Int63n(1<<20)-1<<19, 1)
+			}
+		}
+		w := make(Vector, n)
+		for j := range w {
+			w[j] = new(big.Rat)
+		}
+		for i := range b {
+			w.sub(b[i], big.NewRat(rnd.Int63n(200)-100, 1))
+		}
+		LLL(b, big.NewRat(3, 4))
+		if v := Closest(b, w); !equal(v, w) {
+			t.Fatalf("closest vector %v, expected %v", v, w)
+		}
+	}
+}